@@ -0,0 +1,168 @@
+package podcast
+
+import (
+	"crypto/sha256"
+	"crypto/sha512"
+	"encoding/base64"
+	"encoding/xml"
+	"hash"
+	"io"
+	"net/url"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Integrity types of the podcast:integrity tag.
+const (
+	IntegrityTypeSRI = "sri"
+	IntegrityTypePGP = "pgp-signature"
+)
+
+// AlternateEnclosure represents a podcast:alternateEnclosure tag, offering
+// another version of the episode's media, e.g. a low bitrate or video
+// version, that can be downloaded from one or more sources.
+//
+// The Item's Enclosure remains the RSS 2.0 fallback for apps that do not
+// support alternate enclosures.
+//
+// Specification: https://github.com/Podcastindex-org/podcast-namespace/blob/main/docs/1.0.md#alternate-enclosure
+type AlternateEnclosure struct {
+	XMLName xml.Name `xml:"podcast:alternateEnclosure"`
+
+	// Type is the MIME type of the media, e.g. audio/opus. (Required)
+	Type string `xml:"type,attr"`
+
+	// Length is the size in Bytes of the media.
+	Length int64 `xml:"length,attr,omitempty"`
+
+	// Bitrate is the average encoding bitrate in bits per second.
+	Bitrate int64 `xml:"bitrate,attr,omitempty"`
+
+	// Height is the height in pixels of a video.
+	Height int `xml:"height,attr,omitempty"`
+
+	// Language is the language of the media when it differs from the feed.
+	Language string `xml:"lang,attr,omitempty"`
+
+	// Title is a short name shown by apps to choose the version, e.g. "Opus".
+	Title string `xml:"title,attr,omitempty"`
+
+	// Rel groups alternate enclosures that are the same media, e.g. a video.
+	Rel string `xml:"rel,attr,omitempty"`
+
+	// Codecs is the RFC 6381 codecs string of the media.
+	Codecs string `xml:"codecs,attr,omitempty"`
+
+	// Default marks the version that is the same as the Item's Enclosure.
+	Default bool `xml:"default,attr,omitempty"`
+
+	// Sources are the URIs the media can be downloaded from, e.g. http(s),
+	// ipfs:// or magnet: links. (Required)
+	Sources []*Source `xml:"podcast:source"`
+
+	// Integrity is an optional checksum or signature of the media.
+	Integrity *Integrity
+}
+
+// Source represents a podcast:source tag, a location of an alternate
+// enclosure's media.
+type Source struct {
+	XMLName     xml.Name `xml:"podcast:source"`
+	URI         string   `xml:"uri,attr"`
+	ContentType string   `xml:"contentType,attr,omitempty"`
+}
+
+// Integrity represents a podcast:integrity tag used by apps to verify the
+// media of an alternate enclosure.
+//
+// Value is either a Subresource Integrity string, e.g. "sha384-...", or a
+// base64 encoded PGP signature.
+type Integrity struct {
+	XMLName xml.Name `xml:"podcast:integrity"`
+	Type    string   `xml:"type,attr"`
+	Value   string   `xml:"value,attr"`
+}
+
+// NewIntegritySRI reads the media from r and returns its Subresource
+// Integrity.  The algorithm is one of sha256 or sha384.
+func NewIntegritySRI(r io.Reader, algorithm string) (*Integrity, error) {
+	var h hash.Hash
+	switch algorithm {
+	case "sha256":
+		h = sha256.New()
+	case "sha384":
+		h = sha512.New384()
+	default:
+		return nil, errors.New("unsupported integrity algorithm " + algorithm)
+	}
+	if _, err := io.Copy(h, r); err != nil {
+		return nil, errors.Wrap(err, "podcast.NewIntegritySRI: io.Copy returned error")
+	}
+
+	return &Integrity{
+		Type:  IntegrityTypeSRI,
+		Value: algorithm + "-" + base64.StdEncoding.EncodeToString(h.Sum(nil)),
+	}, nil
+}
+
+// validateAlternateEnclosure confirms the alternate enclosure has a type,
+// valid sources, a BCP 47 language, which it normalizes, and a well-formed
+// integrity.
+func validateAlternateEnclosure(a *AlternateEnclosure) error {
+	if len(a.Type) == 0 {
+		return errors.New("AlternateEnclosure.Type is required")
+	}
+	if len(a.Sources) == 0 {
+		return errors.New("AlternateEnclosure requires at least one Source")
+	}
+	for _, s := range a.Sources {
+		if s == nil {
+			return errors.New("AlternateEnclosure.Source is nil")
+		}
+		u, err := url.Parse(s.URI)
+		if err != nil || len(u.Scheme) == 0 {
+			return errors.New(s.URI + ": Source.URI must be an absolute URI")
+		}
+	}
+	if len(a.Language) > 0 {
+		tag, err := ParseLanguage(a.Language)
+		if err != nil {
+			return err
+		}
+		a.Language = tag
+	}
+	if a.Integrity != nil {
+		return validateIntegrity(a.Integrity)
+	}
+	return nil
+}
+
+func validateIntegrity(i *Integrity) error {
+	switch i.Type {
+	case IntegrityTypeSRI:
+		for _, prefix := range []string{"sha256-", "sha384-", "sha512-"} {
+			if strings.HasPrefix(i.Value, prefix) && len(i.Value) > len(prefix) {
+				return nil
+			}
+		}
+		return errors.New("Integrity.Value is not a sha256, sha384 or sha512 SRI string")
+	case IntegrityTypePGP:
+		if _, err := base64.StdEncoding.DecodeString(i.Value); err != nil || len(i.Value) == 0 {
+			return errors.New("Integrity.Value is not a base64 PGP signature")
+		}
+		return nil
+	}
+	return errors.New("unsupported Integrity.Type " + i.Type)
+}
+
+// httpSource returns the first source that can be downloaded over http(s),
+// which is the only kind an RSS 2.0 enclosure supports.
+func (a *AlternateEnclosure) httpSource() *Source {
+	for _, s := range a.Sources {
+		if strings.HasPrefix(s.URI, "http://") || strings.HasPrefix(s.URI, "https://") {
+			return s
+		}
+	}
+	return nil
+}
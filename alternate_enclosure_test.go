@@ -0,0 +1,46 @@
+package podcast_test
+
+import (
+	"testing"
+
+	podcast "github.com/podpalinc/rss-feed-generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAddItemAlternateEnclosuresSetDirectly(t *testing.T) {
+	t.Parallel()
+
+	source := []*podcast.Source{{URI: "https://example.com/1.opus"}}
+	tests := []struct {
+		name       string
+		enclosures []*podcast.AlternateEnclosure
+		err        string
+	}{
+		{"nil", []*podcast.AlternateEnclosure{nil}, "title: AlternateEnclosure is nil"},
+		{"missing type", []*podcast.AlternateEnclosure{{Sources: source}}, "title: AlternateEnclosure.Type is required"},
+		{"nil source", []*podcast.AlternateEnclosure{{Type: "audio/opus", Sources: []*podcast.Source{nil}}}, "title: AlternateEnclosure.Source is nil"},
+		{"two defaults", []*podcast.AlternateEnclosure{
+			{Type: "audio/opus", Sources: source, Default: true},
+			{Type: "audio/opus", Sources: source, Default: true},
+		}, "title: only one AlternateEnclosure can be the Default"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+			i := podcast.Item{Title: "title", Description: &podcast.Description{Text: "desc"}, AlternateEnclosures: tt.enclosures}
+			i.AddEnclosure("http://example.com/1.mp3", podcast.MP3, "audio/mpeg", 1)
+
+			// act
+			added, err := p.AddItem(i)
+
+			// assert
+			assert.EqualValues(t, 0, added)
+			assert.EqualError(t, err, tt.err)
+			assert.Len(t, p.Items, 0)
+		})
+	}
+}
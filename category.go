@@ -0,0 +1,288 @@
+package podcast
+
+import "github.com/pkg/errors"
+
+// Category is an Apple Podcasts category or subcategory.
+type Category string
+
+// Apple Podcasts categories.
+const (
+	CategoryArts                    Category = "Arts"
+	CategoryBusiness                Category = "Business"
+	CategoryComedy                  Category = "Comedy"
+	CategoryEducation               Category = "Education"
+	CategoryFiction                 Category = "Fiction"
+	CategoryGovernment              Category = "Government"
+	CategoryHistory                 Category = "History"
+	CategoryHealthAndFitness        Category = "Health & Fitness"
+	CategoryKidsAndFamily           Category = "Kids & Family"
+	CategoryLeisure                 Category = "Leisure"
+	CategoryMusic                   Category = "Music"
+	CategoryNews                    Category = "News"
+	CategoryReligionAndSpirituality Category = "Religion & Spirituality"
+	CategoryScience                 Category = "Science"
+	CategorySocietyAndCulture       Category = "Society & Culture"
+	CategorySports                  Category = "Sports"
+	CategoryTechnology              Category = "Technology"
+	CategoryTrueCrime               Category = "True Crime"
+	CategoryTVAndFilm               Category = "TV & Film"
+)
+
+// Apple Podcasts subcategories, prefixed with the name of their category.
+const (
+	CategoryArtsBooks            Category = "Books"
+	CategoryArtsDesign           Category = "Design"
+	CategoryArtsFashionAndBeauty Category = "Fashion & Beauty"
+	CategoryArtsFood             Category = "Food"
+	CategoryArtsPerformingArts   Category = "Performing Arts"
+	CategoryArtsVisualArts       Category = "Visual Arts"
+
+	CategoryBusinessCareers          Category = "Careers"
+	CategoryBusinessEntrepreneurship Category = "Entrepreneurship"
+	CategoryBusinessInvesting        Category = "Investing"
+	CategoryBusinessManagement       Category = "Management"
+	CategoryBusinessMarketing        Category = "Marketing"
+	CategoryBusinessNonProfit        Category = "Non-Profit"
+
+	CategoryComedyComedyInterviews Category = "Comedy Interviews"
+	CategoryComedyImprov           Category = "Improv"
+	CategoryComedyStandUp          Category = "Stand-Up"
+
+	CategoryEducationCourses          Category = "Courses"
+	CategoryEducationHowTo            Category = "How To"
+	CategoryEducationLanguageLearning Category = "Language Learning"
+	CategoryEducationSelfImprovement  Category = "Self-Improvement"
+
+	CategoryFictionComedyFiction  Category = "Comedy Fiction"
+	CategoryFictionDrama          Category = "Drama"
+	CategoryFictionScienceFiction Category = "Science Fiction"
+
+	CategoryHealthAndFitnessAlternativeHealth Category = "Alternative Health"
+	CategoryHealthAndFitnessFitness           Category = "Fitness"
+	CategoryHealthAndFitnessMedicine          Category = "Medicine"
+	CategoryHealthAndFitnessMentalHealth      Category = "Mental Health"
+	CategoryHealthAndFitnessNutrition         Category = "Nutrition"
+	CategoryHealthAndFitnessSexuality         Category = "Sexuality"
+
+	CategoryKidsAndFamilyEducationForKids Category = "Education for Kids"
+	CategoryKidsAndFamilyParenting        Category = "Parenting"
+	CategoryKidsAndFamilyPetsAndAnimals   Category = "Pets & Animals"
+	CategoryKidsAndFamilyStoriesForKids   Category = "Stories for Kids"
+
+	CategoryLeisureAnimationAndManga Category = "Animation & Manga"
+	CategoryLeisureAutomotive        Category = "Automotive"
+	CategoryLeisureAviation          Category = "Aviation"
+	CategoryLeisureCrafts            Category = "Crafts"
+	CategoryLeisureGames             Category = "Games"
+	CategoryLeisureHobbies           Category = "Hobbies"
+	CategoryLeisureHomeAndGarden     Category = "Home & Garden"
+	CategoryLeisureVideoGames        Category = "Video Games"
+
+	CategoryMusicMusicCommentary Category = "Music Commentary"
+	CategoryMusicMusicHistory    Category = "Music History"
+	CategoryMusicMusicInterviews Category = "Music Interviews"
+
+	CategoryNewsBusinessNews      Category = "Business News"
+	CategoryNewsDailyNews         Category = "Daily News"
+	CategoryNewsEntertainmentNews Category = "Entertainment News"
+	CategoryNewsNewsCommentary    Category = "News Commentary"
+	CategoryNewsPolitics          Category = "Politics"
+	CategoryNewsSportsNews        Category = "Sports News"
+	CategoryNewsTechNews          Category = "Tech News"
+
+	CategoryReligionAndSpiritualityBuddhism     Category = "Buddhism"
+	CategoryReligionAndSpiritualityChristianity Category = "Christianity"
+	CategoryReligionAndSpiritualityHinduism     Category = "Hinduism"
+	CategoryReligionAndSpiritualityIslam        Category = "Islam"
+	CategoryReligionAndSpiritualityJudaism      Category = "Judaism"
+	CategoryReligionAndSpiritualityReligion     Category = "Religion"
+	CategoryReligionAndSpiritualitySpirituality Category = "Spirituality"
+
+	CategoryScienceAstronomy       Category = "Astronomy"
+	CategoryScienceChemistry       Category = "Chemistry"
+	CategoryScienceEarthSciences   Category = "Earth Sciences"
+	CategoryScienceLifeSciences    Category = "Life Sciences"
+	CategoryScienceMathematics     Category = "Mathematics"
+	CategoryScienceNaturalSciences Category = "Natural Sciences"
+	CategoryScienceNature          Category = "Nature"
+	CategorySciencePhysics         Category = "Physics"
+	CategoryScienceSocialSciences  Category = "Social Sciences"
+
+	CategorySocietyAndCultureDocumentary      Category = "Documentary"
+	CategorySocietyAndCulturePersonalJournals Category = "Personal Journals"
+	CategorySocietyAndCulturePhilosophy       Category = "Philosophy"
+	CategorySocietyAndCulturePlacesAndTravel  Category = "Places & Travel"
+	CategorySocietyAndCultureRelationships    Category = "Relationships"
+
+	CategorySportsBaseball      Category = "Baseball"
+	CategorySportsBasketball    Category = "Basketball"
+	CategorySportsCricket       Category = "Cricket"
+	CategorySportsFantasySports Category = "Fantasy Sports"
+	CategorySportsFootball      Category = "Football"
+	CategorySportsGolf          Category = "Golf"
+	CategorySportsHockey        Category = "Hockey"
+	CategorySportsRugby         Category = "Rugby"
+	CategorySportsRunning       Category = "Running"
+	CategorySportsSoccer        Category = "Soccer"
+	CategorySportsSwimming      Category = "Swimming"
+	CategorySportsTennis        Category = "Tennis"
+	CategorySportsVolleyball    Category = "Volleyball"
+	CategorySportsWilderness    Category = "Wilderness"
+	CategorySportsWrestling     Category = "Wrestling"
+
+	CategoryTVAndFilmAfterShows     Category = "After Shows"
+	CategoryTVAndFilmFilmHistory    Category = "Film History"
+	CategoryTVAndFilmFilmInterviews Category = "Film Interviews"
+	CategoryTVAndFilmFilmReviews    Category = "Film Reviews"
+	CategoryTVAndFilmTVReviews      Category = "TV Reviews"
+)
+
+// categoryTaxonomy is the current Apple Podcasts category list, in order.
+var categoryTaxonomy = []struct {
+	category      Category
+	subcategories []Category
+}{
+	{CategoryArts, []Category{CategoryArtsBooks, CategoryArtsDesign, CategoryArtsFashionAndBeauty, CategoryArtsFood, CategoryArtsPerformingArts, CategoryArtsVisualArts}},
+	{CategoryBusiness, []Category{CategoryBusinessCareers, CategoryBusinessEntrepreneurship, CategoryBusinessInvesting, CategoryBusinessManagement, CategoryBusinessMarketing, CategoryBusinessNonProfit}},
+	{CategoryComedy, []Category{CategoryComedyComedyInterviews, CategoryComedyImprov, CategoryComedyStandUp}},
+	{CategoryEducation, []Category{CategoryEducationCourses, CategoryEducationHowTo, CategoryEducationLanguageLearning, CategoryEducationSelfImprovement}},
+	{CategoryFiction, []Category{CategoryFictionComedyFiction, CategoryFictionDrama, CategoryFictionScienceFiction}},
+	{CategoryGovernment, []Category{}},
+	{CategoryHistory, []Category{}},
+	{CategoryHealthAndFitness, []Category{CategoryHealthAndFitnessAlternativeHealth, CategoryHealthAndFitnessFitness, CategoryHealthAndFitnessMedicine, CategoryHealthAndFitnessMentalHealth, CategoryHealthAndFitnessNutrition, CategoryHealthAndFitnessSexuality}},
+	{CategoryKidsAndFamily, []Category{CategoryKidsAndFamilyEducationForKids, CategoryKidsAndFamilyParenting, CategoryKidsAndFamilyPetsAndAnimals, CategoryKidsAndFamilyStoriesForKids}},
+	{CategoryLeisure, []Category{CategoryLeisureAnimationAndManga, CategoryLeisureAutomotive, CategoryLeisureAviation, CategoryLeisureCrafts, CategoryLeisureGames, CategoryLeisureHobbies, CategoryLeisureHomeAndGarden, CategoryLeisureVideoGames}},
+	{CategoryMusic, []Category{CategoryMusicMusicCommentary, CategoryMusicMusicHistory, CategoryMusicMusicInterviews}},
+	{CategoryNews, []Category{CategoryNewsBusinessNews, CategoryNewsDailyNews, CategoryNewsEntertainmentNews, CategoryNewsNewsCommentary, CategoryNewsPolitics, CategoryNewsSportsNews, CategoryNewsTechNews}},
+	{CategoryReligionAndSpirituality, []Category{CategoryReligionAndSpiritualityBuddhism, CategoryReligionAndSpiritualityChristianity, CategoryReligionAndSpiritualityHinduism, CategoryReligionAndSpiritualityIslam, CategoryReligionAndSpiritualityJudaism, CategoryReligionAndSpiritualityReligion, CategoryReligionAndSpiritualitySpirituality}},
+	{CategoryScience, []Category{CategoryScienceAstronomy, CategoryScienceChemistry, CategoryScienceEarthSciences, CategoryScienceLifeSciences, CategoryScienceMathematics, CategoryScienceNaturalSciences, CategoryScienceNature, CategorySciencePhysics, CategoryScienceSocialSciences}},
+	{CategorySocietyAndCulture, []Category{CategorySocietyAndCultureDocumentary, CategorySocietyAndCulturePersonalJournals, CategorySocietyAndCulturePhilosophy, CategorySocietyAndCulturePlacesAndTravel, CategorySocietyAndCultureRelationships}},
+	{CategorySports, []Category{CategorySportsBaseball, CategorySportsBasketball, CategorySportsCricket, CategorySportsFantasySports, CategorySportsFootball, CategorySportsGolf, CategorySportsHockey, CategorySportsRugby, CategorySportsRunning, CategorySportsSoccer, CategorySportsSwimming, CategorySportsTennis, CategorySportsVolleyball, CategorySportsWilderness, CategorySportsWrestling}},
+	{CategoryTechnology, []Category{}},
+	{CategoryTrueCrime, []Category{}},
+	{CategoryTVAndFilm, []Category{CategoryTVAndFilmAfterShows, CategoryTVAndFilmFilmHistory, CategoryTVAndFilmFilmInterviews, CategoryTVAndFilmFilmReviews, CategoryTVAndFilmTVReviews}},
+}
+
+// legacyCategory is the current category and subcategory replacing a
+// category Apple retired in 2019.
+type legacyCategory struct {
+	category    Category
+	subcategory Category
+}
+
+// legacyCategories maps the retired Apple categories to the current ones.
+var legacyCategories = map[string]legacyCategory{
+	"Games & Hobbies":            {CategoryLeisure, ""},
+	"Government & Organizations": {CategoryGovernment, ""},
+	"Health":                     {CategoryHealthAndFitness, ""},
+	"News & Politics":            {CategoryNews, ""},
+	"Science & Medicine":         {CategoryScience, ""},
+	"Sports & Recreation":        {CategorySports, ""},
+}
+
+// legacySubcategories maps the retired Apple subcategories, keyed by their
+// retired or current category, to the current ones.  Some moved to another
+// category, e.g. Technology > Tech News is now News > Tech News.
+var legacySubcategories = map[[2]string]legacyCategory{
+	{"Arts", "Literature"}:                           {CategoryArts, CategoryArtsBooks},
+	{"Business", "Business News"}:                    {CategoryNews, CategoryNewsBusinessNews},
+	{"Business", "Management & Marketing"}:           {CategoryBusiness, CategoryBusinessManagement},
+	{"Business", "Shopping"}:                         {CategoryBusiness, ""},
+	{"Education", "Educational Technology"}:          {CategoryEducation, ""},
+	{"Education", "Higher Education"}:                {CategoryEducation, ""},
+	{"Education", "K-12"}:                            {CategoryEducation, ""},
+	{"Education", "Language Courses"}:                {CategoryEducation, CategoryEducationLanguageLearning},
+	{"Education", "Training"}:                        {CategoryEducation, CategoryEducationCourses},
+	{"Games & Hobbies", "Other Games"}:               {CategoryLeisure, CategoryLeisureGames},
+	{"Government & Organizations", "Local"}:          {CategoryGovernment, ""},
+	{"Government & Organizations", "National"}:       {CategoryGovernment, ""},
+	{"Government & Organizations", "Non-Profit"}:     {CategoryBusiness, CategoryBusinessNonProfit},
+	{"Government & Organizations", "Regional"}:       {CategoryGovernment, ""},
+	{"Health", "Fitness & Nutrition"}:                {CategoryHealthAndFitness, CategoryHealthAndFitnessFitness},
+	{"Health", "Self-Help"}:                          {CategoryEducation, CategoryEducationSelfImprovement},
+	{"Religion & Spirituality", "Other"}:             {CategoryReligionAndSpirituality, CategoryReligionAndSpiritualityReligion},
+	{"Science & Medicine", "Medicine"}:               {CategoryHealthAndFitness, CategoryHealthAndFitnessMedicine},
+	{"Society & Culture", "History"}:                 {CategoryHistory, ""},
+	{"Sports & Recreation", "Amateur"}:               {CategorySports, ""},
+	{"Sports & Recreation", "College & High School"}: {CategorySports, ""},
+	{"Sports & Recreation", "Outdoor"}:               {CategorySports, CategorySportsWilderness},
+	{"Sports & Recreation", "Professional"}:          {CategorySports, ""},
+	{"Technology", "Gadgets"}:                        {CategoryTechnology, ""},
+	{"Technology", "Podcasting"}:                     {CategoryTechnology, ""},
+	{"Technology", "Software How-To"}:                {CategoryTechnology, ""},
+	{"Technology", "Tech News"}:                      {CategoryNews, CategoryNewsTechNews},
+}
+
+// Subcategories returns the subcategories of the category, or nil if it is
+// not a current Apple category or has no subcategories.
+func (c Category) Subcategories() []Category {
+	if n := c.index(); n >= 0 {
+		return categoryTaxonomy[n].subcategories
+	}
+	return nil
+}
+
+// index returns the position of the category in categoryTaxonomy, or -1.
+func (c Category) index() int {
+	for n, t := range categoryTaxonomy {
+		if t.category == c {
+			return n
+		}
+	}
+	return -1
+}
+
+// subcategoryIndex returns the position of the subcategory within the
+// category's subcategories, or -1.
+func (c Category) subcategoryIndex(subcategory Category) int {
+	for n, s := range c.Subcategories() {
+		if s == subcategory {
+			return n
+		}
+	}
+	return -1
+}
+
+// subcategoryParent returns the category the subcategory belongs to.
+// Subcategory names are unique across the taxonomy.
+func subcategoryParent(subcategory Category) (Category, bool) {
+	for _, t := range categoryTaxonomy {
+		for _, s := range t.subcategories {
+			if s == subcategory {
+				return t.category, true
+			}
+		}
+	}
+	return "", false
+}
+
+// LookupCategory returns the current Apple category and subcategory for
+// the names given, mapping the categories Apple retired in 2019 to their
+// replacements.  The subcategory may be empty.
+//
+// The subcategory returned is empty when a retired subcategory was merged
+// into its category, and the category may differ from the one given when a
+// subcategory moved, e.g. Technology > Tech News is now News > Tech News.
+//
+// An error is returned for names that are not in the Apple taxonomy.  As
+// for GenerateFeedString, the names are raw text: & is not escaped as &amp;.
+func LookupCategory(category, subcategory string) (Category, Category, error) {
+	c := Category(category)
+	if c.index() < 0 {
+		legacy, ok := legacyCategories[category]
+		if !ok {
+			return "", "", errors.New(category + ": unknown Apple Podcasts category")
+		}
+		c = legacy.category
+	}
+	if len(subcategory) == 0 {
+		return c, "", nil
+	}
+	if c.subcategoryIndex(Category(subcategory)) >= 0 {
+		return c, Category(subcategory), nil
+	}
+	if legacy, ok := legacySubcategories[[2]string{category, subcategory}]; ok {
+		return legacy.category, legacy.subcategory, nil
+	}
+	return "", "", errors.New(subcategory + ": unknown subcategory of Apple Podcasts category " + category)
+}
@@ -0,0 +1,16 @@
+package podcast
+
+import "encoding/xml"
+
+// ChaptersTypeJSON is the MIME type of the Podcasting 2.0 JSON chapters format.
+const ChaptersTypeJSON = "application/json+chapters"
+
+// Chapters represents a podcast:chapters tag linking an episode to its
+// chapters file.
+//
+// Specification: https://github.com/Podcastindex-org/podcast-namespace/blob/main/docs/1.0.md#chapters
+type Chapters struct {
+	XMLName xml.Name `xml:"podcast:chapters"`
+	URL     string   `xml:"url,attr"`
+	Type    string   `xml:"type,attr"`
+}
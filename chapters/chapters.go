@@ -0,0 +1,169 @@
+// Package chapters models the Podcasting 2.0 JSON chapters format, the
+// document a podcast:chapters tag links an episode to.
+//
+// Chapters are decoded from a document with Decode, read from the chapters
+// embedded in a media file with FromFile, or derived from the timestamps of
+// the show notes with FromShowNotes and FromItem.  Validate checks them
+// before they are written with Encode.
+//
+// Specification: https://github.com/Podcastindex-org/podcast-namespace/blob/main/chapters/jsonChapters.md
+package chapters
+
+import (
+	"encoding/json"
+	"io"
+	"math"
+	"path/filepath"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/podpalinc/rss-feed-generator/probe"
+)
+
+const (
+	// MIMEType of the JSON chapters format.
+	MIMEType = "application/json+chapters"
+
+	// Version of the format written by this package.
+	Version = "1.2.0"
+)
+
+// ErrNoChapters is returned for media without embedded chapters, and show
+// notes without timestamps.
+var ErrNoChapters = errors.New("chapters: no chapters found")
+
+// Chapters is a JSON chapters document.
+type Chapters struct {
+	// Version of the format. (Required)
+	Version string `json:"version"`
+
+	Author      string `json:"author,omitempty"`
+	Title       string `json:"title,omitempty"`
+	PodcastName string `json:"podcastName,omitempty"`
+	Description string `json:"description,omitempty"`
+
+	// FileName of the media the chapters belong to.
+	FileName string `json:"fileName,omitempty"`
+
+	// Waypoints marks the Locations of the chapters as a route to be shown
+	// on a map.
+	Waypoints bool `json:"waypoints,omitempty"`
+
+	// Chapters sorted by StartTime. (Required)
+	Chapters []*Chapter `json:"chapters"`
+}
+
+// Chapter is a chapter of a JSON chapters document.
+type Chapter struct {
+	// StartTime of the chapter in seconds. (Required)
+	StartTime float64 `json:"startTime"`
+
+	// EndTime of the chapter in seconds.
+	EndTime float64 `json:"endTime,omitempty"`
+
+	Title string `json:"title,omitempty"`
+
+	// Img is the url of the image of the chapter.
+	Img string `json:"img,omitempty"`
+
+	// URL of a web page or other resource about the chapter.
+	URL string `json:"url,omitempty"`
+
+	// TOC set to false leaves the chapter out of the table of contents
+	// players show, such as for a chapter only changing the image.
+	TOC *bool `json:"toc,omitempty"`
+
+	Location *Location `json:"location,omitempty"`
+}
+
+// Location is the place a chapter is about or recorded at.
+type Location struct {
+	// Name of the place. (Required)
+	Name string `json:"name"`
+
+	// Geo is the RFC 5870 geo URI of the place. (Required)
+	Geo string `json:"geo"`
+
+	// OSM is the OpenStreetMap identifier of the place.
+	OSM string `json:"osm,omitempty"`
+}
+
+// ImageFunc stores the image embedded in the chapter numbered n from 0 and
+// returns the url it is published at, for the Img of the chapter.
+type ImageFunc func(n int, image *probe.Artwork) (string, error)
+
+// FromFile returns the Chapters embedded in the media file, the ID3 CHAP
+// and CTOC frames of an MP3 file or the chpl atom of an MP4 file, see
+// FromMedia.
+func FromFile(name string, image ImageFunc) (*Chapters, error) {
+	info, err := probe.File(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "chapters.FromFile: probe.File returned error")
+	}
+	c, err := FromMedia(info, image)
+	if err != nil {
+		return nil, errors.Wrap(err, name)
+	}
+	c.FileName = filepath.Base(name)
+	return c, nil
+}
+
+// FromMedia returns the Chapters of the chapters embedded in the media,
+// titled after the media.  Chapters hidden from the table of contents of an
+// MP3 file get a TOC of false.
+//
+// The embedded images are passed to the ImageFunc when it is not nil, and
+// dropped otherwise.
+func FromMedia(info *probe.Info, image ImageFunc) (*Chapters, error) {
+	if len(info.Chapters) == 0 {
+		return nil, ErrNoChapters
+	}
+
+	c := &Chapters{Version: Version, Title: info.Title}
+	for n, mc := range info.Chapters {
+		ch := &Chapter{
+			StartTime: seconds(mc.Start),
+			EndTime:   seconds(mc.End),
+			Title:     mc.Title,
+			URL:       mc.URL,
+		}
+		if mc.Hidden {
+			toc := false
+			ch.TOC = &toc
+		}
+		if mc.Image != nil && image != nil {
+			img, err := image(n, mc.Image)
+			if err != nil {
+				return nil, errors.Wrap(err, "chapters.FromMedia: image returned error")
+			}
+			ch.Img = img
+		}
+		c.Chapters = append(c.Chapters, ch)
+	}
+	return c, nil
+}
+
+// Decode reads a JSON chapters document.
+func Decode(r io.Reader) (*Chapters, error) {
+	var c Chapters
+	if err := json.NewDecoder(r).Decode(&c); err != nil {
+		return nil, errors.Wrap(err, "chapters.Decode: json.Decode returned error")
+	}
+	return &c, nil
+}
+
+// Encode writes the Chapters to the io.Writer as indented JSON.
+func (c *Chapters) Encode(w io.Writer) error {
+	e := json.NewEncoder(w)
+	e.SetEscapeHTML(false)
+	e.SetIndent("", "  ")
+	if err := e.Encode(c); err != nil {
+		return errors.Wrap(err, "chapters.Encode: json.Encode returned error")
+	}
+	return nil
+}
+
+// seconds returns the duration in seconds, to the millisecond.
+func seconds(d time.Duration) float64 {
+	return math.Round(d.Seconds()*1000) / 1000
+}
@@ -0,0 +1,175 @@
+package chapters_test
+
+import (
+	"bytes"
+	"errors"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/podpalinc/rss-feed-generator/chapters"
+	"github.com/podpalinc/rss-feed-generator/probe"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromMedia(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	info := &probe.Info{
+		Title: "Episode 1",
+		Chapters: []*probe.Chapter{
+			{Start: 0, End: 10 * time.Second, Title: "Intro"},
+			{
+				Start: 10 * time.Second, End: 1234567 * time.Millisecond, Title: "Interview",
+				URL:   "https://example.com/guest?a=1&b=2",
+				Image: &probe.Artwork{MIMEType: "image/png", Data: []byte("png")},
+			},
+			{Start: 1234567 * time.Millisecond, End: 1300 * time.Second, Title: "Ad", Hidden: true},
+		},
+	}
+	image := func(n int, a *probe.Artwork) (string, error) {
+		return "https://example.com/" + strconv.Itoa(n) + ".png", nil
+	}
+	var b bytes.Buffer
+
+	// act
+	c, err := chapters.FromMedia(info, image)
+	if !assert.NoError(t, err) {
+		return
+	}
+	err = c.Encode(&b)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, `{
+  "version": "1.2.0",
+  "title": "Episode 1",
+  "chapters": [
+    {
+      "startTime": 0,
+      "endTime": 10,
+      "title": "Intro"
+    },
+    {
+      "startTime": 10,
+      "endTime": 1234.567,
+      "title": "Interview",
+      "img": "https://example.com/1.png",
+      "url": "https://example.com/guest?a=1&b=2"
+    },
+    {
+      "startTime": 1234.567,
+      "endTime": 1300,
+      "title": "Ad",
+      "toc": false
+    }
+  ]
+}
+`, b.String())
+}
+
+func TestFromMediaErrors(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	info := &probe.Info{Chapters: []*probe.Chapter{{Image: &probe.Artwork{}}}}
+	image := func(n int, a *probe.Artwork) (string, error) {
+		return "", errors.New("upload failed")
+	}
+
+	// act
+	_, errNone := chapters.FromMedia(&probe.Info{}, nil)
+	_, errImage := chapters.FromMedia(info, image)
+	c, errNoImage := chapters.FromMedia(info, nil)
+
+	// assert
+	assert.Equal(t, chapters.ErrNoChapters, errNone)
+	assert.EqualError(t, errImage, "chapters.FromMedia: image returned error: upload failed")
+	assert.NoError(t, errNoImage)
+	assert.Len(t, c.Chapters[0].Img, 0)
+}
+
+func TestFromFile(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	// an ID3v2.3 tag with a chapter, and a second of MPEG audio frames
+	chap := append([]byte("chp0\x00"), 0, 0, 0, 0, 0, 0, 0x03, 0xE8, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF)
+	chap = append(chap, "TIT2\x00\x00\x00\x06\x00\x00\x00Intro"...)
+	tag := append([]byte("ID3\x03\x00\x00\x00\x00\x00"), byte(10+len(chap)))
+	tag = append(tag, "CHAP\x00\x00\x00"...)
+	tag = append(tag, byte(len(chap)), 0, 0)
+	tag = append(tag, chap...)
+	frame := make([]byte, 417)
+	copy(frame, "\xFF\xFB\x90\x00")
+	dir, err := ioutil.TempDir("", "chapters")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	name := filepath.Join(dir, "episode.mp3")
+	if err := ioutil.WriteFile(name, append(tag, bytes.Repeat(frame, 39)...), 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	c, err := chapters.FromFile(name, nil)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, &chapters.Chapters{
+		Version:  chapters.Version,
+		FileName: "episode.mp3",
+		Chapters: []*chapters.Chapter{{StartTime: 0, EndTime: 1, Title: "Intro"}},
+	}, c)
+}
+
+func TestDecode(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	doc := `{
+  "version": "1.2.0",
+  "title": "Episode 1",
+  "waypoints": true,
+  "chapters": [
+    {
+      "startTime": 0,
+      "title": "Intro & welcome"
+    },
+    {
+      "startTime": 62.5,
+      "title": "Walk",
+      "toc": false,
+      "location": {
+        "name": "Austin",
+        "geo": "geo:30.2672,-97.7431",
+        "osm": "R113314"
+      }
+    }
+  ]
+}
+`
+	var b bytes.Buffer
+
+	// act
+	c, err := chapters.Decode(strings.NewReader(doc))
+	_, errInvalid := chapters.Decode(strings.NewReader(`{"chapters": {}}`))
+	if !assert.NoError(t, err) {
+		return
+	}
+	errEncode := c.Encode(&b)
+
+	// assert
+	assert.Len(t, c.Chapters, 2)
+	assert.Equal(t, false, *c.Chapters[1].TOC)
+	assert.Equal(t, "Austin", c.Chapters[1].Location.Name)
+	assert.Error(t, errInvalid)
+	assert.NoError(t, errEncode)
+	assert.Equal(t, doc, b.String())
+}
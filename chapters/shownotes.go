@@ -0,0 +1,144 @@
+package chapters
+
+import (
+	"html"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+	podcast "github.com/podpalinc/rss-feed-generator"
+)
+
+var (
+	// timestampPattern matches a show notes line starting with a timestamp,
+	// such as "12:34 – Interview", "[1:02:03] Outro" or "- 05:00 News",
+	// capturing the hours, minutes, seconds and the title.
+	timestampPattern = regexp.MustCompile(`^\s*(?:[-*•]\s*)?[\[(]?(?:(\d{1,2}):)?(\d{1,3}):(\d{2})[\])]?(?:\s*[-–—:|.]\s*|\s+)(\S.*)$`)
+
+	// urlPattern matches an http(s) url in the title of a chapter.
+	urlPattern = regexp.MustCompile(`https?://[^\s<>"()\[\]]+`)
+
+	// blockTagPattern matches the HTML tags breaking the show notes into
+	// lines.
+	blockTagPattern = regexp.MustCompile(`(?i)<\s*/?\s*(?:br|p|li|div|h[1-6]|tr)\b[^>]*>`)
+
+	// hrefPattern matches the href of an HTML link.
+	hrefPattern = regexp.MustCompile(`(?i)<a\s[^>]*\bhref\s*=\s*["']([^"']+)["']`)
+
+	// tagPattern matches any HTML tag.
+	tagPattern = regexp.MustCompile(`<[^>]*>`)
+)
+
+// FromShowNotes derives Chapters from the lines of the show notes starting
+// with a timestamp, as in:
+//
+//	00:00 Intro
+//	12:34 – Interview with Jane https://example.com/jane
+//	[1:02:03] Outro
+//
+// The show notes may be plain text or HTML, whose links give the url of the
+// chapter.  The chapters are sorted by start time and have no end time.  It
+// returns ErrNoChapters when no line starts with a timestamp.
+func FromShowNotes(notes string) (*Chapters, error) {
+	var chapters []*Chapter
+	seen := map[float64]bool{}
+	for _, line := range showNotesLines(notes) {
+		ch := parseTimestampLine(line.text)
+		if ch == nil || seen[ch.StartTime] {
+			continue
+		}
+		if len(ch.URL) == 0 {
+			ch.URL = line.href
+		}
+		seen[ch.StartTime] = true
+		chapters = append(chapters, ch)
+	}
+	if len(chapters) == 0 {
+		return nil, ErrNoChapters
+	}
+
+	sort.SliceStable(chapters, func(a, b int) bool {
+		return chapters[a].StartTime < chapters[b].StartTime
+	})
+	return &Chapters{Version: Version, Chapters: chapters}, nil
+}
+
+// FromItem derives the Chapters of the podcast Item from the timestamps of its
+// Description, or of its EncodedDescription, see FromShowNotes.  They are
+// titled after the Item.
+func FromItem(i *podcast.Item) (*Chapters, error) {
+	var notes string
+	if i.Description != nil {
+		notes = i.Description.Text
+	}
+	if len(strings.TrimSpace(notes)) == 0 && i.EncodedDescription != nil {
+		notes = i.EncodedDescription.Text
+	}
+
+	c, err := FromShowNotes(notes)
+	if err != nil {
+		return nil, errors.Wrap(err, i.Title)
+	}
+	c.Title = i.Title
+	return c, nil
+}
+
+// showNotesLine is a line of text of the show notes, with the href of its
+// first link for HTML show notes.
+type showNotesLine struct {
+	text string
+	href string
+}
+
+// showNotesLines splits the show notes into lines of text, breaking HTML
+// show notes at their block tags.
+func showNotesLines(notes string) []showNotesLine {
+	if !tagPattern.MatchString(notes) {
+		var lines []showNotesLine
+		for _, l := range strings.Split(notes, "\n") {
+			lines = append(lines, showNotesLine{text: l})
+		}
+		return lines
+	}
+
+	var lines []showNotesLine
+	for _, block := range blockTagPattern.Split(notes, -1) {
+		for _, l := range strings.Split(block, "\n") {
+			line := showNotesLine{text: html.UnescapeString(tagPattern.ReplaceAllString(l, ""))}
+			if m := hrefPattern.FindStringSubmatch(l); m != nil {
+				line.href = html.UnescapeString(m[1])
+			}
+			lines = append(lines, line)
+		}
+	}
+	return lines
+}
+
+// parseTimestampLine returns the Chapter of a line starting with a timestamp,
+// or nil for other lines.  A url in the title becomes the URL of the chapter.
+func parseTimestampLine(line string) *Chapter {
+	m := timestampPattern.FindStringSubmatch(line)
+	if m == nil {
+		return nil
+	}
+	hours, _ := strconv.Atoi(m[1])
+	minutes, _ := strconv.Atoi(m[2])
+	secs, _ := strconv.Atoi(m[3])
+	if secs >= 60 || (len(m[1]) > 0 && minutes >= 60) {
+		return nil
+	}
+
+	ch := &Chapter{
+		StartTime: float64(hours*3600 + minutes*60 + secs),
+		Title:     strings.TrimSpace(m[4]),
+	}
+	if u := urlPattern.FindString(ch.Title); len(u) > 0 && isHTTPURL(u) {
+		ch.URL = u
+		title := strings.Replace(ch.Title, u, "", 1)
+		title = strings.NewReplacer("()", "", "[]", "", "<>", "").Replace(title)
+		ch.Title = strings.Trim(title, " \t-–—:|")
+	}
+	return ch
+}
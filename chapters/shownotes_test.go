@@ -0,0 +1,118 @@
+package chapters_test
+
+import (
+	"testing"
+
+	"github.com/pkg/errors"
+	podcast "github.com/podpalinc/rss-feed-generator"
+	"github.com/podpalinc/rss-feed-generator/chapters"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFromShowNotes(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tests := []struct {
+		name  string
+		notes string
+		want  []*chapters.Chapter
+	}{
+		{
+			name: "plain text",
+			notes: "In this episode we talk to Jane.\n\n" +
+				"00:00 Intro\n" +
+				"12:34 – Interview with Jane https://example.com/jane\n" +
+				"- 45:00 - Listener questions\n" +
+				"[1:02:03] Outro\n" +
+				"Recorded at 10:30 am\n" +
+				"99:99 Not a timestamp\n",
+			want: []*chapters.Chapter{
+				{StartTime: 0, Title: "Intro"},
+				{StartTime: 754, Title: "Interview with Jane", URL: "https://example.com/jane"},
+				{StartTime: 2700, Title: "Listener questions"},
+				{StartTime: 3723, Title: "Outro"},
+			},
+		},
+		{
+			name: "html",
+			notes: `<p>Chapters:</p><ul>` +
+				`<li>(00:00) Intro</li>` +
+				`<li>05:10 | <a href="https://example.com/?a=1&amp;b=2">News &amp; notes</a></li>` +
+				`</ul><p>01:02:03: Outro<br/>1:75:00 Nope</p>`,
+			want: []*chapters.Chapter{
+				{StartTime: 0, Title: "Intro"},
+				{StartTime: 310, Title: "News & notes", URL: "https://example.com/?a=1&b=2"},
+				{StartTime: 3723, Title: "Outro"},
+			},
+		},
+		{
+			name:  "out of order and repeated",
+			notes: "10:00 Second\n00:00 First\n10:00 Second again",
+			want: []*chapters.Chapter{
+				{StartTime: 0, Title: "First"},
+				{StartTime: 600, Title: "Second"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			c, err := chapters.FromShowNotes(tt.notes)
+
+			// assert
+			if assert.NoError(t, err) {
+				assert.Equal(t, chapters.Version, c.Version)
+				assert.Equal(t, tt.want, c.Chapters)
+				assert.Len(t, c.Validate(), 0)
+			}
+		})
+	}
+}
+
+func TestFromShowNotesNoChapters(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	notes := "<p>No timestamps here, only a 10:30 am start.</p>"
+
+	// act
+	c, err := chapters.FromShowNotes(notes)
+
+	// assert
+	assert.Nil(t, c)
+	assert.Equal(t, chapters.ErrNoChapters, err)
+}
+
+func TestFromItem(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	i := podcast.Item{Title: "Episode 1"}
+	i.AddDescription(podcast.Description{Text: "00:00 Intro\n01:00 Main"})
+	encoded := podcast.Item{
+		Title:              "Episode 2",
+		EncodedDescription: &podcast.EncodedContent{Text: "<p>00:00 Intro</p>"},
+	}
+	empty := podcast.Item{Title: "Episode 3"}
+
+	// act
+	c, err := chapters.FromItem(&i)
+	cEncoded, errEncoded := chapters.FromItem(&encoded)
+	_, errEmpty := chapters.FromItem(&empty)
+
+	// assert
+	if assert.NoError(t, err) {
+		assert.Equal(t, "Episode 1", c.Title)
+		assert.Len(t, c.Chapters, 2)
+	}
+	if assert.NoError(t, errEncoded) {
+		assert.Equal(t, "Intro", cEncoded.Chapters[0].Title)
+	}
+	assert.EqualError(t, errEmpty, "Episode 3: chapters: no chapters found")
+	assert.Equal(t, chapters.ErrNoChapters, errors.Cause(errEmpty))
+}
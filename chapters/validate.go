@@ -0,0 +1,169 @@
+package chapters
+
+import (
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var (
+	// versionPattern matches the 1.x versions of the format.
+	versionPattern = regexp.MustCompile(`^1\.\d+(\.\d+)?$`)
+
+	// osmPattern matches an OpenStreetMap type and id, with an optional
+	// revision, such as R113314 or W43264049#5.
+	osmPattern = regexp.MustCompile(`^[NWR]\d+(#\d+)?$`)
+)
+
+// Issue is a problem found in the Chapters by Validate.
+type Issue struct {
+	// Field is the path of the property at fault, e.g. "version" or
+	// "chapters[2].startTime".
+	Field string
+
+	// Warning marks an issue players cope with, such as a chapter without a
+	// title.  Other issues are errors.
+	Warning bool
+
+	Message string
+}
+
+// String formats the issue as "severity: field: message".
+func (i Issue) String() string {
+	severity := "error"
+	if i.Warning {
+		severity = "warning"
+	}
+	return severity + ": " + i.Field + ": " + i.Message
+}
+
+// Validate checks the Chapters against the specification of the format,
+// returning the issues found:
+//
+//   - the version is required and must be a 1.x version;
+//   - there must be a chapter, and start times must not go back, nor repeat
+//     between chapters of the table of contents;
+//   - end times must be after start times;
+//   - the table of contents should not hide every chapter, and its chapters
+//     should have a title;
+//   - img and url must be http(s) urls;
+//   - locations need a name and an RFC 5870 geo URI, and their osm must be an
+//     OpenStreetMap type and id.
+func (c *Chapters) Validate() []Issue {
+	var issues []Issue
+	add := func(field string, warning bool, message string) {
+		issues = append(issues, Issue{Field: field, Warning: warning, Message: message})
+	}
+
+	if len(c.Version) == 0 {
+		add("version", false, "version is required")
+	} else if !versionPattern.MatchString(c.Version) {
+		add("version", false, c.Version+" is not a 1.x version")
+	}
+	if len(c.Chapters) == 0 {
+		add("chapters", false, "a chapter is required")
+		return issues
+	}
+
+	previous, previousTOC := -1.0, -1.0
+	listed, located := 0, 0
+	for n, ch := range c.Chapters {
+		field := "chapters[" + strconv.Itoa(n) + "]"
+		if ch == nil {
+			add(field, false, "chapter is null")
+			continue
+		}
+
+		start := formatSeconds(ch.StartTime)
+		switch {
+		case ch.StartTime < 0:
+			add(field+".startTime", false, "startTime "+start+" is negative")
+		case ch.StartTime < previous:
+			add(field+".startTime", false, "startTime "+start+" is before the startTime of the previous chapter")
+		case ch.StartTime == previousTOC && ch.inTOC():
+			add(field+".startTime", false, "startTime "+start+" is the startTime of the previous chapter of the table of contents")
+		}
+		previous = ch.StartTime
+		if ch.inTOC() {
+			previousTOC = ch.StartTime
+		}
+
+		if ch.EndTime != 0 && ch.EndTime <= ch.StartTime {
+			add(field+".endTime", false, "endTime "+formatSeconds(ch.EndTime)+" is not after the startTime "+start)
+		}
+		if ch.inTOC() {
+			listed++
+			if len(strings.TrimSpace(ch.Title)) == 0 {
+				add(field+".title", true, "title is recommended for chapters of the table of contents")
+			}
+		}
+		if len(ch.Img) > 0 && !isHTTPURL(ch.Img) {
+			add(field+".img", false, ch.Img+" is not an http(s) url")
+		}
+		if len(ch.URL) > 0 && !isHTTPURL(ch.URL) {
+			add(field+".url", false, ch.URL+" is not an http(s) url")
+		}
+
+		if l := ch.Location; l != nil {
+			located++
+			if len(strings.TrimSpace(l.Name)) == 0 {
+				add(field+".location.name", false, "name is required")
+			}
+			if len(l.Geo) == 0 {
+				add(field+".location.geo", false, "geo is required")
+			} else if !isGeoURI(l.Geo) {
+				add(field+".location.geo", false, l.Geo+" is not an RFC 5870 geo URI")
+			}
+			if len(l.OSM) > 0 && !osmPattern.MatchString(l.OSM) {
+				add(field+".location.osm", false, l.OSM+" is not an OpenStreetMap type and id")
+			}
+		}
+	}
+
+	if listed == 0 {
+		add("chapters", false, "every chapter is hidden from the table of contents")
+	}
+	if c.Waypoints && located == 0 {
+		add("waypoints", true, "waypoints is set but no chapter has a location")
+	}
+	return issues
+}
+
+// inTOC reports whether the chapter is listed in the table of contents.
+func (ch *Chapter) inTOC() bool {
+	return ch.TOC == nil || *ch.TOC
+}
+
+// formatSeconds formats the seconds as the JSON number.
+func formatSeconds(s float64) string {
+	return strconv.FormatFloat(s, 'f', -1, 64)
+}
+
+// isHTTPURL reports whether the url is an absolute http(s) url.
+func isHTTPURL(rawurl string) bool {
+	u, err := url.Parse(rawurl)
+	return err == nil && (u.Scheme == "http" || u.Scheme == "https") && len(u.Host) > 0
+}
+
+// isGeoURI reports whether the uri is an RFC 5870 geo URI of latitude,
+// longitude and optional altitude, such as "geo:37.786971,-122.399677;u=35".
+func isGeoURI(uri string) bool {
+	if len(uri) < 4 || !strings.EqualFold(uri[:4], "geo:") {
+		return false
+	}
+	coords := strings.SplitN(uri[4:], ";", 2)[0]
+	parts := strings.Split(coords, ",")
+	if len(parts) < 2 || len(parts) > 3 {
+		return false
+	}
+	var values [3]float64
+	for n, p := range parts {
+		v, err := strconv.ParseFloat(p, 64)
+		if err != nil {
+			return false
+		}
+		values[n] = v
+	}
+	return values[0] >= -90 && values[0] <= 90 && values[1] >= -180 && values[1] <= 180
+}
@@ -0,0 +1,113 @@
+package chapters_test
+
+import (
+	"testing"
+
+	"github.com/podpalinc/rss-feed-generator/chapters"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestValidate(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	hidden := false
+	tests := []struct {
+		name     string
+		chapters *chapters.Chapters
+		want     []string
+	}{
+		{
+			name: "valid",
+			chapters: &chapters.Chapters{
+				Version:   "1.2.0",
+				Waypoints: true,
+				Chapters: []*chapters.Chapter{
+					{StartTime: 0, Title: "Intro", Img: "https://example.com/0.png"},
+					{StartTime: 0, TOC: &hidden, Img: "https://example.com/1.png"},
+					{StartTime: 12.5, EndTime: 60, Title: "Walk", URL: "http://example.com",
+						Location: &chapters.Location{Name: "Austin", Geo: "geo:30.2672,-97.7431;u=35", OSM: "R113314"}},
+				},
+			},
+		},
+		{
+			name:     "missing version and chapters",
+			chapters: &chapters.Chapters{},
+			want: []string{
+				"error: version: version is required",
+				"error: chapters: a chapter is required",
+			},
+		},
+		{
+			name: "invalid",
+			chapters: &chapters.Chapters{
+				Version:   "2.0",
+				Waypoints: true,
+				Chapters: []*chapters.Chapter{
+					{StartTime: 10, Title: "Intro"},
+					{StartTime: 5, EndTime: 5, Img: "ftp://example.com/1.png"},
+					nil,
+					{StartTime: 5, Title: "Again", URL: "/relative"},
+					{StartTime: -1, TOC: &hidden},
+				},
+			},
+			want: []string{
+				"error: version: 2.0 is not a 1.x version",
+				"error: chapters[1].startTime: startTime 5 is before the startTime of the previous chapter",
+				"error: chapters[1].endTime: endTime 5 is not after the startTime 5",
+				"warning: chapters[1].title: title is recommended for chapters of the table of contents",
+				"error: chapters[1].img: ftp://example.com/1.png is not an http(s) url",
+				"error: chapters[2]: chapter is null",
+				"error: chapters[3].startTime: startTime 5 is the startTime of the previous chapter of the table of contents",
+				"error: chapters[3].url: /relative is not an http(s) url",
+				"error: chapters[4].startTime: startTime -1 is negative",
+				"warning: waypoints: waypoints is set but no chapter has a location",
+			},
+		},
+		{
+			name: "locations",
+			chapters: &chapters.Chapters{
+				Version: "1.2",
+				Chapters: []*chapters.Chapter{
+					{Title: "Nowhere", Location: &chapters.Location{}},
+					{StartTime: 1, Title: "Pole", Location: &chapters.Location{Name: "Pole", Geo: "geo:91,0", OSM: "X1"}},
+					{StartTime: 2, Title: "Address", Location: &chapters.Location{Name: "Home", Geo: "1 Main Street"}},
+				},
+			},
+			want: []string{
+				"error: chapters[0].location.name: name is required",
+				"error: chapters[0].location.geo: geo is required",
+				"error: chapters[1].location.geo: geo:91,0 is not an RFC 5870 geo URI",
+				"error: chapters[1].location.osm: X1 is not an OpenStreetMap type and id",
+				"error: chapters[2].location.geo: 1 Main Street is not an RFC 5870 geo URI",
+			},
+		},
+		{
+			name: "all hidden",
+			chapters: &chapters.Chapters{
+				Version:  "1.2.0",
+				Chapters: []*chapters.Chapter{{TOC: &hidden}},
+			},
+			want: []string{
+				"error: chapters: every chapter is hidden from the table of contents",
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			issues := tt.chapters.Validate()
+
+			// assert
+			var got []string
+			for _, i := range issues {
+				got = append(got, i.String())
+			}
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
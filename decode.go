@@ -0,0 +1,249 @@
+package podcast
+
+import (
+	"encoding/xml"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	ext "github.com/podpalinc/rss-feed-generator/parser/extensions"
+	"golang.org/x/net/html/charset"
+)
+
+// namespacePrefixes maps the namespaces this package knows how to encode to
+// the element prefixes used in the struct tags.
+var namespacePrefixes = map[string]string{
+	ATOMNS:       "atom",
+	ITUNESNS:     "itunes",
+	GOOGLEPLAYNS: "googleplay",
+	SPOTIFYNS:    "spotify",
+	CONTENT:      "content",
+	PODCASTNS:    "podcast",
+}
+
+// Decode reads an RSS 2.0 podcast feed from the io.Reader and returns the
+// Podcast it describes.
+//
+// The namespaced elements (itunes:, content:, atom:, etc.) are matched by
+// their namespace URI rather than by the prefix used in the document, so feeds
+// produced by other hosts decode the same as those produced by Encode.
+// Elements this package does not model are kept in the Extensions of the
+// Podcast or Item, and the namespaces they use in the Namespaces.
+//
+// Values are stored exactly as they appear in the feed and the formatted
+// fields (Enclosure.LengthFormatted, Enclosure.TypeFormatted) are used to
+// rebuild Enclosure.Length and Enclosure.Type.  The typed date, duration,
+// explicit and number fields are only set when they format back to the same
+// value.  This makes it safe to Decode a feed, modify it and Encode it again:
+// a feed generated by Encode is reproduced byte for byte.
+func Decode(r io.Reader) (*Podcast, error) {
+	d := xml.NewTokenDecoder(newPrefixReader(r))
+
+	var wrapped decodeWrapper
+	if err := d.Decode(&wrapped); err != nil {
+		return nil, errors.Wrap(err, "podcast.Decode: d.Decode returned error")
+	}
+	if wrapped.Channel == nil || wrapped.Channel.Podcast == nil {
+		return nil, errors.New("podcast.Decode: feed has no channel")
+	}
+
+	p := wrapped.Channel.Podcast
+	p.encode = encoder
+	decodeAtomLinks(p, wrapped.Channel.AtomLinks)
+	for _, a := range wrapped.Namespaces {
+		prefix := strings.TrimPrefix(a.Name.Local, "xmlns:")
+		if prefix == a.Name.Local || isKnownNamespace(a.Value) {
+			continue
+		}
+		if err := p.AddNamespace(prefix, a.Value); err != nil {
+			return nil, errors.Wrap(err, "podcast.Decode: p.AddNamespace returned error")
+		}
+	}
+	p.PubDateTime, _ = decodeDate(p.PubDate)
+	p.LastBuildDateTime, _ = decodeDate(p.LastBuildDate)
+	p.Explicit = decodeExplicit(p.IExplicit, p.GooglePlayExplicit)
+	for _, i := range p.Items {
+		decodeItem(i)
+	}
+	for _, li := range p.LiveItems {
+		decodeLiveItem(li)
+	}
+	return p, nil
+}
+
+// decodeWrapper is the rss element read by Decode.
+type decodeWrapper struct {
+	XMLName    xml.Name       `xml:"rss"`
+	Namespaces []xml.Attr     `xml:",any,attr"`
+	Channel    *decodeChannel `xml:"channel"`
+}
+
+// decodeChannel is the channel read by Decode, keeping every atom:link where
+// the AtomLink of the Podcast only holds one.
+type decodeChannel struct {
+	XMLName   xml.Name    `xml:"channel"`
+	AtomLinks []*AtomLink `xml:"atom:link"`
+	*Podcast
+}
+
+// decodeAtomLinks sets the AtomLink of the Podcast to the first self link,
+// and keeps the other links, such as the next and prev links of paged feeds,
+// as Extensions ahead of the others.
+func decodeAtomLinks(p *Podcast, links []*AtomLink) {
+	p.AtomLink = nil
+	var extensions []*Extension
+	for _, l := range links {
+		if p.AtomLink == nil && l.Rel == "self" {
+			p.AtomLink = l
+			continue
+		}
+		attrs := map[string]string{}
+		for name, value := range map[string]string{"href": l.HREF, "rel": l.Rel, "type": l.Type} {
+			if len(value) > 0 {
+				attrs[name] = value
+			}
+		}
+		extensions = append(extensions, &Extension{
+			Prefix:    "atom",
+			Extension: ext.Extension{Name: "link", Attrs: attrs},
+		})
+	}
+	if len(extensions) > 0 {
+		p.Extensions = append(extensions, p.Extensions...)
+	}
+}
+
+// isKnownNamespace reports whether the namespace is one of those this
+// package encodes, whose elements are decoded into the built-in fields.
+func isKnownNamespace(uri string) bool {
+	for ns := range namespacePrefixes {
+		if strings.EqualFold(ns, uri) {
+			return true
+		}
+	}
+	return false
+}
+
+// decodeItem restores the typed fields of the Item that are encoded from
+// formatted fields.
+func decodeItem(i *Item) {
+	i.PubDateTime, _ = decodeDate(i.PubDate)
+	if d, ok := parseITunesDuration(i.IDuration); ok && d >= time.Second &&
+		parseDuration(int64(d/time.Second)) == i.IDuration {
+		i.Duration = d
+	}
+	i.Explicit = decodeExplicit(i.IExplicit, i.GooglePlayExplicit)
+	i.Season = decodeNumber(i.SeasonNumber)
+	i.Episode = decodeNumber(i.EpisodeNumber)
+	decodeEnclosure(i.Enclosure)
+	for _, s := range i.Soundbites {
+		decodeSoundbite(s)
+	}
+}
+
+// decodeDate parses an RFC 1123Z date, as formatted by Encode.
+func decodeDate(date string) (time.Time, bool) {
+	t, err := time.Parse(time.RFC1123Z, date)
+	if err != nil || t.Format(time.RFC1123Z) != date {
+		return time.Time{}, false
+	}
+	return t, true
+}
+
+// decodeExplicit parses the itunes:explicit and googleplay:explicit pair, as
+// formatted by Encode, or returns nil.
+func decodeExplicit(explicit, googlePlay string) *bool {
+	b := explicit == "yes"
+	if googlePlayYesNo(b) != explicit || explicit != googlePlay {
+		return nil
+	}
+	return &b
+}
+
+// decodeNumber parses a positive season or episode number, or returns 0.
+func decodeNumber(number string) int {
+	n, err := strconv.Atoi(number)
+	if err != nil || n <= 0 || strconv.Itoa(n) != number {
+		return 0
+	}
+	return n
+}
+
+// decodeEnclosure restores the typed Enclosure fields from the formatted
+// attributes read from the feed.
+func decodeEnclosure(e *Enclosure) {
+	if e == nil {
+		return
+	}
+	if l, err := strconv.ParseInt(strings.TrimSpace(e.LengthFormatted), 10, 64); err == nil {
+		e.Length = l
+	}
+	e.Type = e.Type.GetEnclosureType(e.TypeFormatted)
+}
+
+// prefixReader is an xml.TokenReader that rewrites namespaced names into the
+// "prefix:local" form used by the struct tags of this package, e.g. the
+// element {http://www.itunes.com/dtds/podcast-1.0.dtd}author becomes
+// itunes:author regardless of the prefix chosen by the feed's author.
+type prefixReader struct {
+	d *xml.Decoder
+
+	// namespaces holds the namespace URI declared for each document prefix.
+	namespaces map[string]string
+}
+
+func newPrefixReader(r io.Reader) *prefixReader {
+	d := xml.NewDecoder(r)
+	d.CharsetReader = charset.NewReaderLabel
+	d.Entity = xml.HTMLEntity
+	return &prefixReader{d: d, namespaces: map[string]string{}}
+}
+
+// Token implements xml.TokenReader.
+func (pr *prefixReader) Token() (xml.Token, error) {
+	t, err := pr.d.RawToken()
+	if err != nil {
+		return t, err
+	}
+
+	switch tt := t.(type) {
+	case xml.StartElement:
+		attrs := make([]xml.Attr, 0, len(tt.Attr))
+		for _, a := range tt.Attr {
+			if a.Name.Space == "xmlns" {
+				pr.namespaces[a.Name.Local] = a.Value
+				a.Name.Local = "xmlns:" + a.Name.Local
+				a.Name.Space = ""
+			} else {
+				a.Name = pr.name(a.Name)
+			}
+			attrs = append(attrs, a)
+		}
+		return xml.StartElement{Name: pr.name(tt.Name), Attr: attrs}, nil
+	case xml.EndElement:
+		return xml.EndElement{Name: pr.name(tt.Name)}, nil
+	}
+	return xml.CopyToken(t), nil
+}
+
+// name maps a raw, prefixed xml.Name into its canonical "prefix:local" form.
+//
+// Prefixes bound to a known namespace are replaced with the prefix this
+// package encodes, while unknown or undeclared prefixes are kept as-is.
+func (pr *prefixReader) name(n xml.Name) xml.Name {
+	if n.Space == "" {
+		return n
+	}
+	prefix := n.Space
+	if uri, ok := pr.namespaces[prefix]; ok {
+		for ns, p := range namespacePrefixes {
+			if strings.EqualFold(ns, uri) {
+				prefix = p
+				break
+			}
+		}
+	}
+	return xml.Name{Local: prefix + ":" + n.Local}
+}
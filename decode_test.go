@@ -0,0 +1,265 @@
+package podcast_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	podcast "github.com/podpalinc/rss-feed-generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func newDecodeTestPodcast() podcast.Podcast {
+	p := podcast.New("Q&A Show", "http://example.com/", podcast.Description{Text: "desc <b>bold</b>"}, nil, nil)
+	p.AddAtomLink("http://example.com/feed.rss")
+	p.AddAuthor([]string{"Jane", "Joe"})
+	p.AddOwner("Jane", "jane@example.com")
+	p.AddCategory("Arts", []string{"Books", "Design"})
+	p.AddCategory("Comedy", nil)
+	p.AddImage("http://example.com/i.jpg")
+	p.AddLanguage("en-us")
+	p.AddCopyright("© Podpal")
+	p.AddSubTitle("A subtitle")
+	p.AddParentalAdvisory(podcast.ParentalAdvisoryClean)
+	p.AddItunesType("episodic")
+	p.AddPubDate("Sun, 14 Mar 2021 18:34:05 +0000")
+	_ = p.AddPerson(podcast.Person{Name: "Jane", Role: podcast.PersonRoleHost})
+	p.AddFunding("https://example.com/donate", "Donate")
+	p.AddSpotifyLimit(10)
+	_ = p.AddSpotifyCountryOfOrigin("us", "gb")
+	_ = p.AddValue(podcast.Value{
+		Type:       podcast.ValueTypeLightning,
+		Method:     podcast.ValueMethodKeysend,
+		Recipients: []*podcast.ValueRecipient{{Type: podcast.RecipientTypeLNAddress, Address: "jane@example.com", Split: 100}},
+	})
+
+	for _, n := range []string{"1", "2"} {
+		i := podcast.Item{Title: "Episode " + n}
+		i.AddDescription(podcast.Description{Text: "<p>Notes for " + n + "</p>"})
+		i.AddEnclosure("http://example.com/"+n+".m4a", podcast.M4A, "audio/x-m4a", 1234)
+		i.AddEpisodeNumber(2)
+		i.AddEpisodeType(podcast.EpisodeTypeFull)
+		i.AddDuration(533)
+		i.AddSummary("summary " + n)
+		i.AddPubDate("Sun, 14 Mar 2021 18:34:05 +0000")
+		i.AddChapters("http://example.com/"+n+".json", "")
+		_ = i.AddSoundbite(10*time.Second, 1500*time.Millisecond, "clip "+n)
+		_ = i.AddTranscript("http://example.com/"+n+".vtt", podcast.TranscriptTypeVTT, "en", true)
+		if _, err := p.AddItem(i); err != nil {
+			panic(err)
+		}
+	}
+
+	li := podcast.LiveItem{
+		Status: podcast.LiveItemStatusLive,
+		Start:  time.Date(2021, time.September, 26, 7, 30, 0, 0, time.UTC),
+	}
+	li.AddTitle("Live")
+	li.AddGUID("live-1")
+	li.AddEnclosure("http://example.com/live.mp3", podcast.MP3, "audio/mpeg", 0)
+	li.AddContentLink("http://example.com/watch", "Watch")
+	if _, err := p.AddLiveItem(li); err != nil {
+		panic(err)
+	}
+	return p
+}
+
+func TestDecodeRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newDecodeTestPodcast()
+	var first bytes.Buffer
+	assert.NoError(t, p.Encode(&first))
+
+	// act
+	d, err := podcast.Decode(bytes.NewReader(first.Bytes()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	var second bytes.Buffer
+	assert.NoError(t, d.Encode(&second))
+
+	// assert
+	assert.Equal(t, first.String(), second.String())
+	assert.Len(t, d.Items, 2)
+	assert.EqualValues(t, 1234, d.Items[0].Enclosure.Length)
+	assert.Equal(t, podcast.M4A, d.Items[0].Enclosure.Type)
+	assert.Equal(t, "jane@example.com", d.IOwner.Email)
+	assert.Equal(t, "Books", d.ICategories[0].ICategories[0].Text)
+	assert.Equal(t, "summary 1", d.Items[0].ISummary.Text)
+	assert.Equal(t, "2021-09-26T07:30:00Z", d.LiveItems[0].Start.Format(time.RFC3339))
+	assert.Equal(t, "http://example.com/watch", d.LiveItems[0].ContentLinks[0].Href)
+	assert.Equal(t, 100, d.Values[0].Recipients[0].Split)
+	assert.Equal(t, 1500*time.Millisecond, d.Items[0].Soundbites[0].Duration)
+	assert.Equal(t, podcast.TranscriptRelCaptions, d.Items[0].Transcripts[0].Rel)
+}
+
+func TestDecodeForeignPrefixes(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	feed := `<?xml version="1.0" encoding="UTF-8"?>
+<rss version="2.0" xmlns:it="http://www.itunes.com/DTDs/Podcast-1.0.dtd" xmlns:c="http://purl.org/rss/1.0/modules/content/">
+  <channel>
+    <title>Other Host</title>
+    <it:author>Someone</it:author>
+    <it:category text="Technology"/>
+    <item>
+      <title>One</title>
+      <c:encoded><![CDATA[<p>notes</p>]]></c:encoded>
+      <enclosure url="http://example.com/1.mp3" length=" 42 " type="audio/mpeg"/>
+      <it:duration>10:00</it:duration>
+    </item>
+  </channel>
+</rss>`
+
+	// act
+	p, err := podcast.Decode(strings.NewReader(feed))
+
+	// assert
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, "Someone", p.IAuthor)
+	assert.Equal(t, "Technology", p.ICategories[0].Text)
+	assert.Equal(t, "<p>notes</p>", p.Items[0].EncodedDescription.Text)
+	assert.Equal(t, "10:00", p.Items[0].IDuration)
+	assert.EqualValues(t, 42, p.Items[0].Enclosure.Length)
+	assert.Equal(t, podcast.MP3, p.Items[0].Enclosure.Type)
+}
+
+func TestDecodeInvalidXML(t *testing.T) {
+	t.Parallel()
+
+	// act
+	p, err := podcast.Decode(strings.NewReader("<rss><channel>"))
+
+	// assert
+	assert.Nil(t, p)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "podcast.Decode")
+}
+
+func TestDecodeNoChannel(t *testing.T) {
+	t.Parallel()
+
+	// act
+	p, err := podcast.Decode(strings.NewReader(`<rss version="2.0"></rss>`))
+
+	// assert
+	assert.Nil(t, p)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "no channel")
+}
+
+func TestDecodeTypedFields(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	published := time.Date(2021, time.March, 14, 18, 34, 5, 0, time.UTC)
+	p := podcast.New("title", "http://example.com/", podcast.Description{Text: "desc"}, &published, nil)
+	p.AddExplicit(true)
+	i := podcast.Item{Title: "Episode"}
+	i.AddEnclosure("http://example.com/1.mp3", podcast.MP3, "audio/mpeg", 1234)
+	i.AddPubDateTime(published)
+	i.AddDurationTime(533 * time.Second)
+	i.AddExplicit(false)
+	i.AddSeason(1)
+	i.AddEpisode(3)
+	if _, err := p.AddItem(i); !assert.NoError(t, err) {
+		return
+	}
+	want := p.String()
+
+	// act
+	d, err := podcast.Decode(strings.NewReader(want))
+
+	// assert
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.True(t, published.Equal(d.PubDateTime))
+	if assert.NotNil(t, d.Explicit) {
+		assert.True(t, *d.Explicit)
+	}
+	di := d.Items[0]
+	assert.True(t, published.Equal(di.PubDateTime))
+	assert.Equal(t, 533*time.Second, di.Duration)
+	if assert.NotNil(t, di.Explicit) {
+		assert.False(t, *di.Explicit)
+	}
+	assert.Equal(t, 1, di.Season)
+	assert.Equal(t, 3, di.Episode)
+	assert.Equal(t, want, d.String())
+}
+
+func TestDecodeAtomLinks(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newPagedTestPodcast(t, 4)
+	pages, err := p.Pages(podcast.PageOptions{PageSize: 1, URLTemplate: "http://example.com/archive/{page}.xml"})
+	if !assert.NoError(t, err) {
+		return
+	}
+	var page bytes.Buffer
+	assert.NoError(t, pages[0].Encode(&page))
+
+	// act
+	d, err := podcast.Decode(bytes.NewReader(page.Bytes()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	var first bytes.Buffer
+	assert.NoError(t, d.Encode(&first))
+	again, err := podcast.Decode(bytes.NewReader(first.Bytes()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	var second bytes.Buffer
+	assert.NoError(t, again.Encode(&second))
+
+	// assert
+	assert.Equal(t, "http://example.com/feed.xml", d.AtomLink.HREF)
+	assert.Equal(t, "self", d.AtomLink.Rel)
+	var rels []string
+	for _, x := range d.Extensions {
+		if x.Prefix == "atom" && x.Name == "link" {
+			rels = append(rels, x.Attrs["rel"]+" "+x.Attrs["href"])
+		}
+	}
+	assert.Equal(t, []string{
+		"first http://example.com/feed.xml",
+		"next http://example.com/archive/3.xml",
+		"last http://example.com/archive/1.xml",
+		"prev-archive http://example.com/archive/3.xml",
+	}, rels)
+	assert.Contains(t, first.String(), `<atom:link href="http://example.com/archive/3.xml" rel="next" type="application/rss+xml"></atom:link>`)
+	assert.Equal(t, first.String(), second.String())
+}
+
+func TestDecodeGUIDPermaLink(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	feed := `<rss version="2.0"><channel><title>title</title>` +
+		`<item><title>One</title><link>http://example.com/1</link><guid>http://example.com/1</guid></item>` +
+		`<item><title>Two</title><link>http://example.com/2</link><guid isPermaLink="false">two</guid></item>` +
+		`</channel></rss>`
+
+	// act
+	p, err := podcast.Decode(strings.NewReader(feed))
+	if !assert.NoError(t, err) {
+		return
+	}
+	var b bytes.Buffer
+	assert.NoError(t, p.Encode(&b))
+
+	// assert
+	assert.True(t, p.Items[0].GUID.IsPermaLink)
+	assert.False(t, p.Items[1].GUID.IsPermaLink)
+	assert.Contains(t, b.String(), `<guid isPermaLink="true">http://example.com/1</guid>`)
+	assert.Contains(t, b.String(), `<guid isPermaLink="false">two</guid>`)
+}
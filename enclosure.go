@@ -1,7 +1,16 @@
 package podcast
 
 import (
+	"bytes"
 	"encoding/xml"
+	"io"
+	"mime"
+	"net/url"
+	"os"
+	"path"
+	"strings"
+
+	"github.com/pkg/errors"
 )
 
 // EnclosureType specifies the type of the enclosure.
@@ -13,53 +22,208 @@ const (
 	MOV
 	PDF
 	EPUB
+	AAC
+	OPUS
+	OGG
+	FLAC
+	WAV
+	AIFF
+	WEBA
+	WEBM
+	OGV
+	MKV
+	HLS
+	DASH
+
+	// UnknownEnclosureType is the type of media none of the EnclosureTypes
+	// match.  Set the Enclosure.TypeFormatted of such media.
+	UnknownEnclosureType EnclosureType = -1
 )
 
 const (
 	enclosureDefault = "application/octet-stream"
+
+	// sniffLen is the number of bytes DetectEnclosureType reads.
+	sniffLen = 512
 )
 
 // EnclosureType specifies the type of the enclosure.
 type EnclosureType int
 
+// enclosureTypes holds the MIME type of each EnclosureType, followed by the
+// other MIME types it is known by, and the file extensions of its media.
+var enclosureTypes = []struct {
+	t          EnclosureType
+	mimeTypes  []string
+	extensions []string
+}{
+	// https://help.apple.com/itc/podcasts_connect/#/itcb54353390
+	{M4A, []string{"audio/x-m4a", "audio/m4a", "audio/mp4", "audio/x-m4b"}, []string{".m4a", ".m4b"}},
+	{M4V, []string{"video/x-m4v"}, []string{".m4v"}},
+	{MP4, []string{"video/mp4", "application/mp4"}, []string{".mp4"}},
+	{MP3, []string{"audio/mpeg", "audio/mp3", "audio/mpeg3", "audio/x-mpeg", "audio/x-mp3", "audio/mpg"}, []string{".mp3"}},
+	{MOV, []string{"video/quicktime"}, []string{".mov", ".qt"}},
+	{PDF, []string{"application/pdf"}, []string{".pdf"}},
+	{EPUB, []string{"document/x-epub", "application/epub+zip"}, []string{".epub"}},
+	{AAC, []string{"audio/aac", "audio/x-aac", "audio/aacp"}, []string{".aac"}},
+	{OPUS, []string{"audio/opus"}, []string{".opus"}},
+	{OGG, []string{"audio/ogg", "application/ogg", "audio/vorbis"}, []string{".ogg", ".oga"}},
+	{FLAC, []string{"audio/flac", "audio/x-flac"}, []string{".flac"}},
+	{WAV, []string{"audio/wav", "audio/x-wav", "audio/wave", "audio/vnd.wave"}, []string{".wav"}},
+	{AIFF, []string{"audio/aiff", "audio/x-aiff"}, []string{".aif", ".aiff", ".aifc"}},
+	{WEBA, []string{"audio/webm"}, []string{".weba"}},
+	{WEBM, []string{"video/webm"}, []string{".webm"}},
+	{OGV, []string{"video/ogg"}, []string{".ogv"}},
+	{MKV, []string{"video/x-matroska"}, []string{".mkv"}},
+	{HLS, []string{"application/vnd.apple.mpegurl", "application/x-mpegurl", "audio/mpegurl", "audio/x-mpegurl"}, []string{".m3u8"}},
+	{DASH, []string{"application/dash+xml"}, []string{".mpd"}},
+}
+
+// GetEnclosureType returns the EnclosureType of the MIME type, ignoring its
+// case and parameters, or UnknownEnclosureType.  Ogg media with the opus
+// codecs parameter is OPUS.
 func (et EnclosureType) GetEnclosureType(enclosureType string) EnclosureType {
-	switch enclosureType {
-	case "audio/x-m4a":
-		return M4A
-	case "audio/mpeg":
-		return MP3
-	case "video/x-m4v":
-		return M4V
-	case "video/mp4":
-		return MP4
-	case "video/quicktime":
-		return MOV
+	mediaType, params, err := mime.ParseMediaType(enclosureType)
+	if err != nil {
+		return UnknownEnclosureType
+	}
+	if mediaType == "audio/ogg" && strings.Contains(strings.ToLower(params["codecs"]), "opus") {
+		return OPUS
 	}
-	return MP3
+	for _, e := range enclosureTypes {
+		for _, m := range e.mimeTypes {
+			if m == mediaType {
+				return e.t
+			}
+		}
+	}
+	return UnknownEnclosureType
 }
 
 // String returns the MIME type encoding of the specified EnclosureType.
 func (et EnclosureType) String() string {
-	// https://help.apple.com/itc/podcasts_connect/#/itcb54353390
-	switch et {
-	case M4A:
-		return "audio/x-m4a"
-	case M4V:
-		return "video/x-m4v"
-	case MP4:
-		return "video/mp4"
-	case MP3:
-		return "audio/mpeg"
-	case MOV:
-		return "video/quicktime"
-	case PDF:
-		return "application/pdf"
-	case EPUB:
-		return "document/x-epub"
+	for _, e := range enclosureTypes {
+		if e.t == et {
+			return e.mimeTypes[0]
+		}
 	}
 	return enclosureDefault
 }
 
+// EnclosureTypeFromURL returns the EnclosureType of the file extension of
+// the url, ignoring its query, or UnknownEnclosureType.
+func EnclosureTypeFromURL(rawurl string) EnclosureType {
+	p := rawurl
+	if u, err := url.Parse(rawurl); err == nil {
+		p = u.Path
+	}
+	ext := strings.ToLower(path.Ext(p))
+	if len(ext) == 0 {
+		return UnknownEnclosureType
+	}
+	for _, e := range enclosureTypes {
+		for _, x := range e.extensions {
+			if x == ext {
+				return e.t
+			}
+		}
+	}
+	return UnknownEnclosureType
+}
+
+// DetectEnclosureType returns the EnclosureType of the media by sniffing its
+// first bytes, or UnknownEnclosureType if they are not recognized.  WebM
+// media is always detected as the WEBM video.
+func DetectEnclosureType(r io.Reader) (EnclosureType, error) {
+	b := make([]byte, sniffLen)
+	n, err := io.ReadFull(r, b)
+	if err != nil && err != io.EOF && err != io.ErrUnexpectedEOF {
+		return UnknownEnclosureType, errors.Wrap(err, "podcast.DetectEnclosureType: io.ReadFull returned error")
+	}
+	return sniffEnclosureType(b[:n]), nil
+}
+
+// DetectEnclosureTypeFile returns the EnclosureType of the local file by
+// sniffing its first bytes, falling back to its file extension.
+func DetectEnclosureTypeFile(name string) (EnclosureType, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return UnknownEnclosureType, errors.Wrap(err, "podcast.DetectEnclosureTypeFile: os.Open returned error")
+	}
+	defer f.Close()
+
+	t, err := DetectEnclosureType(f)
+	if err != nil {
+		return UnknownEnclosureType, errors.Wrap(err, name)
+	}
+	if t == UnknownEnclosureType {
+		t = EnclosureTypeFromURL(name)
+	}
+	return t, nil
+}
+
+// sniffEnclosureType matches the signatures of the media formats.
+func sniffEnclosureType(b []byte) EnclosureType {
+	at := func(offset int, sig string) bool {
+		return len(b) >= offset+len(sig) && string(b[offset:offset+len(sig)]) == sig
+	}
+
+	switch {
+	case at(0, "ID3"):
+		return MP3
+	case len(b) >= 2 && b[0] == 0xFF && b[1]&0xF6 == 0xF0:
+		// ADTS frame: 12 sync bits and layer 0
+		return AAC
+	case len(b) >= 2 && b[0] == 0xFF && b[1]&0xE6 == 0xE2:
+		// MPEG audio frame: 11 sync bits and layer III
+		return MP3
+	case at(4, "ftyp"):
+		switch {
+		case at(8, "M4A "), at(8, "M4B "), at(8, "M4P "):
+			return M4A
+		case at(8, "M4V"):
+			return M4V
+		case at(8, "qt  "):
+			return MOV
+		}
+		return MP4
+	case at(4, "moov"), at(4, "mdat"), at(4, "wide"):
+		return MOV
+	case at(0, "OggS"):
+		switch {
+		case at(28, "OpusHead"):
+			return OPUS
+		case at(28, "\x80theora"):
+			return OGV
+		}
+		return OGG
+	case at(0, "fLaC"):
+		return FLAC
+	case at(0, "RIFF") && at(8, "WAVE"):
+		return WAV
+	case at(0, "FORM") && (at(8, "AIFF") || at(8, "AIFC")):
+		return AIFF
+	case at(0, "\x1A\x45\xDF\xA3"):
+		if bytes.Contains(b, []byte("matroska")) {
+			return MKV
+		}
+		return WEBM
+	case at(0, "%PDF-"):
+		return PDF
+	case at(0, "PK\x03\x04") && at(30, "mimetypeapplication/epub+zip"):
+		return EPUB
+	}
+
+	text := bytes.TrimLeft(bytes.TrimPrefix(b, []byte("\xEF\xBB\xBF")), " \t\r\n")
+	switch {
+	case bytes.HasPrefix(text, []byte("#EXTM3U")):
+		return HLS
+	case bytes.HasPrefix(text, []byte("<")) && bytes.Contains(text, []byte("<MPD")):
+		return DASH
+	}
+	return UnknownEnclosureType
+}
+
 // Enclosure represents a download enclosure.
 type Enclosure struct {
 	XMLName xml.Name `xml:"enclosure"`
@@ -78,6 +242,7 @@ type Enclosure struct {
 	Type EnclosureType `xml:"-"`
 	// TypeFormatted is MIME type encoding of the download. (Required)
 	//
-	// This field gets overwritten with the API when setting Type.
+	// This field gets overwritten with the API when setting Type, unless
+	// it is UnknownEnclosureType.
 	TypeFormatted string `xml:"type,attr"`
 }
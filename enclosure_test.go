@@ -1,6 +1,10 @@
 package podcast_test
 
 import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
 	"testing"
 
 	podcast "github.com/podpalinc/rss-feed-generator"
@@ -21,6 +25,19 @@ var enclosureTests = []enclosureTest{
 	{podcast.PDF, "application/pdf"},
 	{podcast.EPUB, "document/x-epub"},
 	{podcast.M4A, "audio/x-m4a"},
+	{podcast.AAC, "audio/aac"},
+	{podcast.OPUS, "audio/opus"},
+	{podcast.OGG, "audio/ogg"},
+	{podcast.FLAC, "audio/flac"},
+	{podcast.WAV, "audio/wav"},
+	{podcast.AIFF, "audio/aiff"},
+	{podcast.WEBA, "audio/webm"},
+	{podcast.WEBM, "video/webm"},
+	{podcast.OGV, "video/ogg"},
+	{podcast.MKV, "video/x-matroska"},
+	{podcast.HLS, "application/vnd.apple.mpegurl"},
+	{podcast.DASH, "application/dash+xml"},
+	{podcast.UnknownEnclosureType, "application/octet-stream"},
 	{99, "application/octet-stream"},
 }
 
@@ -35,3 +52,262 @@ func TestEnclosureTypes(t *testing.T) {
 		})
 	}
 }
+
+func TestGetEnclosureType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		mimeType string
+		want     podcast.EnclosureType
+	}{
+		{"audio/mpeg", podcast.MP3},
+		{"audio/MP3", podcast.MP3},
+		{"audio/mp4", podcast.M4A},
+		{"application/epub+zip", podcast.EPUB},
+		{"audio/x-wav", podcast.WAV},
+		{"audio/ogg", podcast.OGG},
+		{"audio/ogg; codecs=opus", podcast.OPUS},
+		{"application/x-mpegURL", podcast.HLS},
+		{"audio/x-unknown", podcast.UnknownEnclosureType},
+		{"", podcast.UnknownEnclosureType},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.mimeType, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			got := podcast.EnclosureType(0).GetEnclosureType(tt.mimeType)
+
+			// assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestEnclosureTypeFromURL(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		url  string
+		want podcast.EnclosureType
+	}{
+		{"http://example.com/1.mp3", podcast.MP3},
+		{"http://example.com/1.MP3?token=a.b", podcast.MP3},
+		{"https://example.com/1.opus#t=10", podcast.OPUS},
+		{"https://example.com/live/index.m3u8", podcast.HLS},
+		{"/home/jane/episode.flac", podcast.FLAC},
+		{"http://example.com/1.txt", podcast.UnknownEnclosureType},
+		{"http://example.com/download", podcast.UnknownEnclosureType},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.url, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			got := podcast.EnclosureTypeFromURL(tt.url)
+
+			// assert
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDetectEnclosureType(t *testing.T) {
+	t.Parallel()
+
+	ogg := func(codec string) string {
+		return "OggS" + string(make([]byte, 24)) + codec
+	}
+	tests := []struct {
+		name string
+		data string
+		want podcast.EnclosureType
+	}{
+		{"id3", "ID3\x04\x00", podcast.MP3},
+		{"mpeg frame", "\xFF\xFB\x90\x64", podcast.MP3},
+		{"adts", "\xFF\xF1\x50\x80", podcast.AAC},
+		{"m4a", "\x00\x00\x00\x20ftypM4A \x00\x00\x00\x00", podcast.M4A},
+		{"m4v", "\x00\x00\x00\x20ftypM4VH\x00\x00\x00\x00", podcast.M4V},
+		{"mov", "\x00\x00\x00\x14ftypqt  \x00\x00\x00\x00", podcast.MOV},
+		{"mp4", "\x00\x00\x00\x20ftypisom\x00\x00\x02\x00", podcast.MP4},
+		{"opus", ogg("OpusHead"), podcast.OPUS},
+		{"vorbis", ogg("\x01vorbis"), podcast.OGG},
+		{"theora", ogg("\x80theora"), podcast.OGV},
+		{"flac", "fLaC\x00\x00\x00\x22", podcast.FLAC},
+		{"wav", "RIFF\x24\x08\x00\x00WAVEfmt ", podcast.WAV},
+		{"aiff", "FORM\x00\x00\x00\x00AIFFCOMM", podcast.AIFF},
+		{"webm", "\x1A\x45\xDF\xA3\x9F\x42\x86\x81\x01\x42\x82\x84webm", podcast.WEBM},
+		{"mkv", "\x1A\x45\xDF\xA3\xA3\x42\x86\x81\x01\x42\x82\x88matroska", podcast.MKV},
+		{"pdf", "%PDF-1.7", podcast.PDF},
+		{"epub", "PK\x03\x04" + string(make([]byte, 26)) + "mimetypeapplication/epub+zip", podcast.EPUB},
+		{"hls", "\xEF\xBB\xBF#EXTM3U\n#EXT-X-VERSION:3", podcast.HLS},
+		{"dash", "<?xml version=\"1.0\"?>\n<MPD xmlns=\"urn:mpeg:dash:schema:mpd:2011\">", podcast.DASH},
+		{"text", "hello", podcast.UnknownEnclosureType},
+		{"empty", "", podcast.UnknownEnclosureType},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			got, err := podcast.DetectEnclosureType(bytes.NewReader([]byte(tt.data)))
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestDetectEnclosureTypeFile(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	dir, err := ioutil.TempDir("", "enclosure")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	sniffed := filepath.Join(dir, "episode.bin")
+	named := filepath.Join(dir, "episode.aac")
+	for name, data := range map[string]string{sniffed: "fLaC\x00\x00\x00\x22", named: "unrecognized"} {
+		if err := ioutil.WriteFile(name, []byte(data), 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// act
+	gotSniffed, errSniffed := podcast.DetectEnclosureTypeFile(sniffed)
+	gotNamed, errNamed := podcast.DetectEnclosureTypeFile(named)
+	_, errMissing := podcast.DetectEnclosureTypeFile(filepath.Join(dir, "missing.mp3"))
+
+	// assert
+	assert.NoError(t, errSniffed)
+	assert.Equal(t, podcast.FLAC, gotSniffed)
+	assert.NoError(t, errNamed)
+	assert.Equal(t, podcast.AAC, gotNamed)
+	assert.Error(t, errMissing)
+}
+
+func TestAddEnclosureURL(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	i := podcast.Item{Title: "title"}
+
+	// act
+	errUnknown := i.AddEnclosureURL("http://example.com/download", 1)
+	err := i.AddEnclosureURL("http://example.com/1.opus", 1234)
+
+	// assert
+	assert.EqualError(t, errUnknown, "http://example.com/download: unknown enclosure type, see AddEnclosureType")
+	assert.NoError(t, err)
+	assert.Equal(t, podcast.OPUS, i.Enclosure.Type)
+	assert.EqualValues(t, 1234, i.Enclosure.Length)
+}
+
+func TestAddEnclosureTypeString(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+	i := podcast.Item{Title: "title", Description: &podcast.Description{Text: "desc"}}
+	i.AddEnclosure("http://example.com/1.mp3", podcast.MP3, "application/octet-stream", 1)
+
+	// act
+	_, err := p.AddItem(i)
+
+	// assert
+	assert.EqualError(t, err, "title: Enclosure.Type is required")
+}
+
+func TestAddItemUnknownEnclosureType(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+	i := podcast.Item{Title: "title", Description: &podcast.Description{Text: "desc"}}
+	i.AddEnclosureType("http://example.com/1.spx", podcast.UnknownEnclosureType, 1)
+	i.Enclosure.TypeFormatted = "audio/speex"
+
+	// act
+	_, err := p.AddItem(i)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "audio/speex", p.Items[0].Enclosure.TypeFormatted)
+}
+
+func TestDecodeEnclosureTypeRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	feed := `<rss version="2.0"><channel><title>title</title>` +
+		`<item><title>Episode</title><enclosure url="http://example.com/1.ogg" length="1" type="audio/ogg"></enclosure></item>` +
+		`<item><title>Episode</title><enclosure url="http://example.com/1.spx" length="1" type="audio/speex"></enclosure></item>` +
+		`</channel></rss>`
+
+	// act
+	p, err := podcast.Decode(bytes.NewReader([]byte(feed)))
+
+	// assert
+	assert.NoError(t, err)
+	if assert.Len(t, p.Items, 2) {
+		assert.Equal(t, podcast.OGG, p.Items[0].Enclosure.Type)
+		assert.Equal(t, podcast.UnknownEnclosureType, p.Items[1].Enclosure.Type)
+		assert.Contains(t, p.String(), `type="audio/ogg"`)
+		assert.Contains(t, p.String(), `type="audio/speex"`)
+	}
+}
+
+func TestAddEnclosureFile(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	dir, err := ioutil.TempDir("", "enclosure")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	// 100 MPEG-1 layer III frames of 128 kbps at 44.1 kHz, 2.6 seconds
+	frame := make([]byte, 417)
+	copy(frame, "\xFF\xFB\x90\x00")
+	mp3 := filepath.Join(dir, "episode.mp3")
+	pdf := filepath.Join(dir, "notes.pdf")
+	txt := filepath.Join(dir, "notes.txt")
+	for name, data := range map[string][]byte{
+		mp3: bytes.Repeat(frame, 100),
+		pdf: []byte("%PDF-1.7"),
+		txt: []byte("notes"),
+	} {
+		if err := ioutil.WriteFile(name, data, 0600); err != nil {
+			t.Fatal(err)
+		}
+	}
+	var i, notes, unknown podcast.Item
+
+	// act
+	err = i.AddEnclosureFile("http://example.com/1.mp3", mp3)
+	errNotes := notes.AddEnclosureFile("http://example.com/1.pdf", pdf)
+	errUnknown := unknown.AddEnclosureFile("http://example.com/1.txt", txt)
+
+	// assert
+	assert.NoError(t, err)
+	if assert.NotNil(t, i.Enclosure) {
+		assert.Equal(t, "http://example.com/1.mp3", i.Enclosure.URL)
+		assert.Equal(t, podcast.MP3, i.Enclosure.Type)
+		assert.EqualValues(t, 41700, i.Enclosure.Length)
+	}
+	assert.Equal(t, "3", i.IDuration)
+	assert.NoError(t, errNotes)
+	if assert.NotNil(t, notes.Enclosure) {
+		assert.Equal(t, podcast.PDF, notes.Enclosure.Type)
+		assert.EqualValues(t, 8, notes.Enclosure.Length)
+	}
+	assert.Len(t, notes.IDuration, 0)
+	assert.Error(t, errUnknown)
+	assert.Nil(t, unknown.Enclosure)
+}
@@ -0,0 +1,240 @@
+package podcast
+
+import (
+	"bytes"
+	"encoding/xml"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// ItemOrder is the order EncodeWithOptions writes the Items in.
+type ItemOrder int
+
+// Item orders.  Whichever the order, Items with a numeric IOrder come first,
+// sorted by it, as Apple Podcasts does.
+const (
+	// ItemOrderInsertion writes the Items in the order they were added.
+	ItemOrderInsertion ItemOrder = iota
+	// ItemOrderNewestFirst writes the most recently published Items first.
+	ItemOrderNewestFirst
+	// ItemOrderOldestFirst writes the earliest published Items first.
+	ItemOrderOldestFirst
+	// ItemOrderSerial writes the Items by season and episode number, then
+	// oldest first.
+	ItemOrderSerial
+	// ItemOrderAuto is ItemOrderSerial when the Podcast IType is "serial",
+	// and ItemOrderNewestFirst otherwise.
+	ItemOrderAuto
+)
+
+// EncodeOptions controls how Podcast.EncodeWithOptions writes the feed.
+//
+// The zero value writes the feed as Podcast.Encode does.  Either way the
+// output is deterministic: the namespaces are declared, and the attributes
+// written, in a fixed order, so encoding the same Podcast twice gives the
+// same bytes.
+type EncodeOptions struct {
+	// Clock returns the current time, against which scheduled Items are
+	// evaluated.  Without a Clock only the published Items are written.
+	Clock func() time.Time
+
+	// UpdateChannelDates sets the channel PubDate and LastBuildDate to the
+	// date of the newest Item written, when it is more recent than them.
+	UpdateChannelDates bool
+
+	// Order of the Items, defaults to ItemOrderInsertion.
+	Order ItemOrder
+
+	// MaxItems limits the feed to the first Items in Order.  Zero or less
+	// writes them all.
+	MaxItems int
+
+	// MaxBytes limits the size of the feed by dropping the last Items in
+	// Order until it fits.  An error is returned if the feed does not fit
+	// without Items.  Zero or less does not limit the size.
+	MaxBytes int
+
+	// ExcludeBlocked leaves out the Items blocked from iTunes with IBlock.
+	ExcludeBlocked bool
+
+	// Minify writes the feed without indentation.
+	Minify bool
+}
+
+// visible reports whether the Item is written at the time of the Clock.
+func (o EncodeOptions) visible(i *Item) bool {
+	if o.Clock == nil {
+		return i.State == ItemStatePublished
+	}
+	return i.Visible(o.Clock())
+}
+
+// EncodeWithOptions writes the bytes to the io.Writer stream in RSS 2.0
+// specification, as controlled by the EncodeOptions.
+//
+// Drafts and unpublished Items are never written.  Scheduled Items appear
+// once the Clock passes their PubDateTime, and never without a Clock.
+func (p *Podcast) EncodeWithOptions(w io.Writer, o EncodeOptions) error {
+	e := p.encodable(o)
+
+	encode := p.encode
+	if encode == nil {
+		encode = encoder
+	}
+	if o.Minify {
+		encode = minifiedEncoder
+	}
+
+	if o.MaxBytes <= 0 {
+		if _, err := w.Write([]byte(HEADER)); err != nil {
+			return errors.Wrap(err, "podcast.Encode: w.Write return error")
+		}
+		return encode(w, NewWrapper(e))
+	}
+
+	// find the most Items that fit in MaxBytes
+	items := e.Items
+	size := func(n int) (*bytes.Buffer, error) {
+		e.Items = items[:n]
+		b := bytes.NewBufferString(HEADER)
+		err := encode(b, NewWrapper(e))
+		return b, err
+	}
+	b, err := size(len(items))
+	if err != nil {
+		return err
+	}
+	if b.Len() > o.MaxBytes {
+		n := sort.Search(len(items), func(n int) bool {
+			b, err := size(n)
+			return err != nil || b.Len() > o.MaxBytes
+		}) - 1
+		if n < 0 {
+			return errors.New("podcast.EncodeWithOptions: feed is larger than MaxBytes " + strconv.Itoa(o.MaxBytes))
+		}
+		if b, err = size(n); err != nil {
+			return err
+		}
+	}
+	if _, err := b.WriteTo(w); err != nil {
+		return errors.Wrap(err, "podcast.Encode: w.Write return error")
+	}
+	return nil
+}
+
+// encodable returns the formatted copy of the Podcast with only the Items
+// to write, in order.
+func (p *Podcast) encodable(o EncodeOptions) *Podcast {
+	e := p.formatted()
+
+	var newest time.Time
+	items := make([]*Item, 0, len(e.Items))
+	for n, i := range e.Items {
+		if !o.visible(p.Items[n]) {
+			continue
+		}
+		if o.ExcludeBlocked && isYes(i.IBlock) {
+			continue
+		}
+		items = append(items, i)
+		if t, ok := i.publishTime(); ok && t.After(newest) {
+			newest = t
+		}
+	}
+	e.Items = items
+
+	if o.UpdateChannelDates && !newest.IsZero() {
+		if t, ok := parseRFC2822Date(e.PubDate); !ok || newest.After(t) {
+			e.PubDate = newest.Format(time.RFC1123Z)
+		}
+		if t, ok := parseRFC2822Date(e.LastBuildDate); !ok || newest.After(t) {
+			e.LastBuildDate = newest.Format(time.RFC1123Z)
+		}
+	}
+
+	sortItems(e.Items, o.order(e.IType))
+
+	if o.MaxItems > 0 && len(e.Items) > o.MaxItems {
+		e.Items = e.Items[:o.MaxItems]
+	}
+	return e
+}
+
+// order returns the Order to write the Items of a Podcast of the iTunes
+// type in, resolving ItemOrderAuto.
+func (o EncodeOptions) order(itype string) ItemOrder {
+	if o.Order != ItemOrderAuto {
+		return o.Order
+	}
+	if strings.EqualFold(itype, "serial") {
+		return ItemOrderSerial
+	}
+	return ItemOrderNewestFirst
+}
+
+// sortItems sorts the Items in the order, keeping the insertion order of
+// the Items that compare equal.
+func sortItems(items []*Item, order ItemOrder) {
+	if order == ItemOrderInsertion {
+		return
+	}
+	sort.SliceStable(items, func(a, b int) bool {
+		return itemLess(items[a], items[b], order)
+	})
+}
+
+// itemLess reports whether Item a is written before Item b in the order.
+func itemLess(a, b *Item, order ItemOrder) bool {
+	aOrder, aOK := itemNumber(a.IOrder)
+	bOrder, bOK := itemNumber(b.IOrder)
+	if aOK || bOK {
+		if aOK && bOK {
+			return aOrder < bOrder
+		}
+		return aOK
+	}
+
+	if order == ItemOrderSerial {
+		as, _ := itemNumber(a.SeasonNumber)
+		bs, _ := itemNumber(b.SeasonNumber)
+		if as != bs {
+			return as < bs
+		}
+		ae, _ := itemNumber(a.EpisodeNumber)
+		be, _ := itemNumber(b.EpisodeNumber)
+		if ae != be {
+			return ae < be
+		}
+	}
+
+	at, _ := a.publishTime()
+	bt, _ := b.publishTime()
+	if order == ItemOrderNewestFirst {
+		return at.After(bt)
+	}
+	return at.Before(bt)
+}
+
+// itemNumber parses the IOrder, SeasonNumber or EpisodeNumber string.
+func itemNumber(number string) (int, bool) {
+	n, err := strconv.Atoi(strings.TrimSpace(number))
+	return n, err == nil
+}
+
+// isYes reports whether the iTunes yes/no value is yes.
+func isYes(value string) bool {
+	return strings.EqualFold(value, "yes") || strings.EqualFold(value, "true")
+}
+
+var minifiedEncoder = func(w io.Writer, o interface{}) error {
+	e := xml.NewEncoder(w)
+	if err := e.Encode(o); err != nil {
+		return errors.Wrap(err, "podcast.minifiedEncoder: e.Encode returned error")
+	}
+	return nil
+}
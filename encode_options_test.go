@@ -0,0 +1,313 @@
+package podcast_test
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	podcast "github.com/podpalinc/rss-feed-generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func newLifecycleTestPodcast(t *testing.T, now time.Time) podcast.Podcast {
+	p := podcast.New("title", "http://example.com/", podcast.Description{Text: "Description"}, nil, nil)
+	p.AddPubDateTime(now.Add(-30 * 24 * time.Hour))
+
+	items := []struct {
+		title string
+		state podcast.ItemState
+		at    time.Time
+	}{
+		{"published", podcast.ItemStatePublished, now.Add(-72 * time.Hour)},
+		{"draft", podcast.ItemStateDraft, now.Add(-48 * time.Hour)},
+		{"scheduled past", podcast.ItemStateScheduled, now.Add(-time.Hour)},
+		{"scheduled future", podcast.ItemStateScheduled, now.Add(time.Hour)},
+		{"unpublished", podcast.ItemStateUnpublished, now.Add(-2 * time.Hour)},
+	}
+	for _, it := range items {
+		i := podcast.Item{Title: it.title, Description: &podcast.Description{Text: "d"}}
+		i.AddEnclosure("http://example.com/"+strings.Replace(it.title, " ", "-", -1)+".mp3", podcast.MP3, "", 1)
+		i.AddPubDateTime(it.at)
+		i.State = it.state
+		if _, err := p.AddItem(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return p
+}
+
+func TestEncodeWithOptionsLifecycle(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	now := time.Date(2021, time.March, 14, 12, 0, 0, 0, time.UTC)
+	p := newLifecycleTestPodcast(t, now)
+	var b bytes.Buffer
+
+	// act
+	err := p.EncodeWithOptions(&b, podcast.EncodeOptions{
+		Clock:              func() time.Time { return now },
+		UpdateChannelDates: true,
+	})
+
+	// assert
+	if !assert.NoError(t, err) {
+		return
+	}
+	out := b.String()
+	assert.Contains(t, out, "<title>published</title>")
+	assert.Contains(t, out, "<title>scheduled past</title>")
+	assert.NotContains(t, out, "<title>draft</title>")
+	assert.NotContains(t, out, "<title>scheduled future</title>")
+	assert.NotContains(t, out, "<title>unpublished</title>")
+	assert.Contains(t, out, "<pubDate>Sun, 14 Mar 2021 11:00:00 +0000</pubDate>\n    <lastBuildDate>Sun, 14 Mar 2021 11:00:00 +0000</lastBuildDate>")
+	assert.Len(t, p.Items, 5)
+	assert.Equal(t, "Fri, 12 Feb 2021 12:00:00 +0000", p.PubDateTime.Format(time.RFC1123Z))
+}
+
+func TestEncodeWithoutClock(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	now := time.Now().UTC()
+	p := newLifecycleTestPodcast(t, now)
+	var b bytes.Buffer
+
+	// act
+	err := p.Encode(&b)
+
+	// assert
+	if !assert.NoError(t, err) {
+		return
+	}
+	out := b.String()
+	assert.Contains(t, out, "<title>published</title>")
+	assert.NotContains(t, out, "<title>draft</title>")
+	assert.NotContains(t, out, "<title>scheduled past</title>")
+	assert.NotContains(t, out, "<title>scheduled future</title>")
+	assert.NotContains(t, out, "<title>unpublished</title>")
+	assert.Contains(t, out, "<pubDate>"+p.PubDateTime.Format(time.RFC1123Z)+"</pubDate>")
+	assert.NotContains(t, out, "<lastBuildDate>")
+}
+
+func TestEncodeWithOptionsScheduledAppears(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	now := time.Date(2021, time.March, 14, 12, 0, 0, 0, time.UTC)
+	p := newLifecycleTestPodcast(t, now)
+	var before, after bytes.Buffer
+
+	// act
+	errBefore := p.EncodeWithOptions(&before, podcast.EncodeOptions{
+		Clock: func() time.Time { return now.Add(time.Hour - time.Second) },
+	})
+	errAfter := p.EncodeWithOptions(&after, podcast.EncodeOptions{
+		Clock:              func() time.Time { return now.Add(time.Hour) },
+		UpdateChannelDates: true,
+	})
+
+	// assert
+	assert.NoError(t, errBefore)
+	assert.NoError(t, errAfter)
+	assert.NotContains(t, before.String(), "<title>scheduled future</title>")
+	assert.Contains(t, after.String(), "<title>scheduled future</title>")
+	assert.Contains(t, after.String(), "<lastBuildDate>Sun, 14 Mar 2021 13:00:00 +0000</lastBuildDate>")
+}
+
+func TestEncodeWithOptionsKeepsNewerChannelDate(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	now := time.Date(2021, time.March, 14, 12, 0, 0, 0, time.UTC)
+	p := newLifecycleTestPodcast(t, now)
+	p.AddLastBuildDateTime(now)
+	var b bytes.Buffer
+
+	// act
+	err := p.EncodeWithOptions(&b, podcast.EncodeOptions{
+		Clock:              func() time.Time { return now },
+		UpdateChannelDates: true,
+	})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Contains(t, b.String(), "<lastBuildDate>Sun, 14 Mar 2021 12:00:00 +0000</lastBuildDate>")
+}
+
+func newOrderTestPodcast(t *testing.T) podcast.Podcast {
+	p := podcast.New("title", "http://example.com/", podcast.Description{Text: "Description"}, nil, nil)
+	base := time.Date(2021, time.March, 14, 12, 0, 0, 0, time.UTC)
+
+	items := []struct {
+		title           string
+		days            int
+		season, episode int
+	}{
+		{"b", 2, 1, 2},
+		{"c", 3, 2, 1},
+		{"a", 1, 1, 1},
+		{"d", 0, 0, 0},
+	}
+	for _, it := range items {
+		i := podcast.Item{Title: it.title, Description: &podcast.Description{Text: "d"}}
+		i.AddEnclosure("http://example.com/"+it.title+".mp3", podcast.MP3, "", 1)
+		i.AddPubDateTime(base.AddDate(0, 0, it.days))
+		i.AddSeason(it.season)
+		i.AddEpisode(it.episode)
+		if _, err := p.AddItem(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	p.Items[3].PubDateTime = time.Time{}
+	return p
+}
+
+// encodedTitles returns the titles of the Items in the encoded feed.
+func encodedTitles(t *testing.T, p podcast.Podcast, o podcast.EncodeOptions) []string {
+	var b bytes.Buffer
+	if err := p.EncodeWithOptions(&b, o); err != nil {
+		t.Fatal(err)
+	}
+	d, err := podcast.Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var titles []string
+	for _, i := range d.Items {
+		titles = append(titles, i.Title)
+	}
+	return titles
+}
+
+func TestEncodeWithOptionsOrder(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		order  podcast.ItemOrder
+		iType  string
+		titles []string
+	}{
+		{podcast.ItemOrderInsertion, "", []string{"b", "c", "a", "d"}},
+		{podcast.ItemOrderNewestFirst, "", []string{"c", "b", "a", "d"}},
+		{podcast.ItemOrderOldestFirst, "", []string{"d", "a", "b", "c"}},
+		{podcast.ItemOrderSerial, "", []string{"d", "a", "b", "c"}},
+		{podcast.ItemOrderAuto, "episodic", []string{"c", "b", "a", "d"}},
+		{podcast.ItemOrderAuto, "serial", []string{"d", "a", "b", "c"}},
+	}
+	for _, tt := range tests {
+		p := newOrderTestPodcast(t)
+		p.AddItunesType(tt.iType)
+
+		titles := encodedTitles(t, p, podcast.EncodeOptions{Order: tt.order})
+
+		assert.Equal(t, tt.titles, titles, "order %d %s", tt.order, tt.iType)
+	}
+}
+
+func TestEncodeWithOptionsIOrder(t *testing.T) {
+	t.Parallel()
+
+	p := newOrderTestPodcast(t)
+	p.Items[2].IOrder = "2"
+	p.Items[3].IOrder = "1"
+
+	titles := encodedTitles(t, p, podcast.EncodeOptions{Order: podcast.ItemOrderNewestFirst})
+
+	assert.Equal(t, []string{"d", "a", "c", "b"}, titles)
+}
+
+func TestEncodeWithOptionsMaxItems(t *testing.T) {
+	t.Parallel()
+
+	p := newOrderTestPodcast(t)
+
+	titles := encodedTitles(t, p, podcast.EncodeOptions{Order: podcast.ItemOrderNewestFirst, MaxItems: 2})
+
+	assert.Equal(t, []string{"c", "b"}, titles)
+}
+
+func TestEncodeWithOptionsMaxBytes(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newOrderTestPodcast(t)
+	var full, two bytes.Buffer
+	if err := p.EncodeWithOptions(&full, podcast.EncodeOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.EncodeWithOptions(&two, podcast.EncodeOptions{MaxItems: 2}); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	fits := encodedTitles(t, p, podcast.EncodeOptions{MaxBytes: full.Len()})
+	trimmed := encodedTitles(t, p, podcast.EncodeOptions{MaxBytes: full.Len() - 1})
+	exact := encodedTitles(t, p, podcast.EncodeOptions{MaxBytes: two.Len()})
+	err := p.EncodeWithOptions(&bytes.Buffer{}, podcast.EncodeOptions{MaxBytes: 100})
+
+	// assert
+	assert.Len(t, fits, 4)
+	assert.Len(t, trimmed, 3)
+	assert.Equal(t, []string{"b", "c"}, exact)
+	assert.EqualError(t, err, "podcast.EncodeWithOptions: feed is larger than MaxBytes 100")
+}
+
+func TestEncodeWithOptionsExcludeBlocked(t *testing.T) {
+	t.Parallel()
+
+	p := newOrderTestPodcast(t)
+	p.Items[0].AddItunesBlock("hide")
+	p.Items[1].AddItunesBlock("show")
+
+	blocked := encodedTitles(t, p, podcast.EncodeOptions{})
+	excluded := encodedTitles(t, p, podcast.EncodeOptions{ExcludeBlocked: true})
+
+	assert.Len(t, blocked, 4)
+	assert.Equal(t, []string{"c", "a", "d"}, excluded)
+}
+
+func TestEncodeWithOptionsMinify(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newOrderTestPodcast(t)
+	var indented, minified bytes.Buffer
+
+	// act
+	err1 := p.EncodeWithOptions(&indented, podcast.EncodeOptions{})
+	err2 := p.EncodeWithOptions(&minified, podcast.EncodeOptions{Minify: true})
+
+	// assert
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Contains(t, indented.String(), "\n  <channel>")
+	assert.Equal(t, 1, strings.Count(minified.String(), "\n"))
+	assert.True(t, strings.HasPrefix(minified.String(), podcast.HEADER+"<rss "))
+	assert.Equal(t, encodedTitles(t, p, podcast.EncodeOptions{}), encodedTitles(t, p, podcast.EncodeOptions{Minify: true}))
+}
+
+func TestEncodeDeterministic(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	build := func(categories [][]string) string {
+		p := newOrderTestPodcast(t)
+		for _, c := range categories {
+			if err := p.AddCategory(c[0], c[1:]); err != nil {
+				t.Fatal(err)
+			}
+		}
+		_ = p.AddPerson(podcast.Person{Name: "Jane", Role: podcast.PersonRoleHost, Img: "http://example.com/jane.jpg"})
+		return p.String()
+	}
+
+	// act
+	a := build([][]string{{"Arts", "Books"}, {"News"}, {"Arts", "Design"}})
+	b := build([][]string{{"News"}, {"Arts", "Design", "Books"}})
+
+	// assert
+	assert.Equal(t, a, b)
+	assert.Contains(t, a, `<rss version="2.0" xmlns:atom="http://www.w3.org/2005/Atom" xmlns:itunes="http://www.itunes.com/dtds/podcast-1.0.dtd" xmlns:googleplay="http://www.google.com/schemas/play-podcasts/1.0" xmlns:spotify="http://www.spotify.com/ns/rss" xmlns:content="http://purl.org/rss/1.0/modules/content/" xmlns:podcast="https://podcastindex.org/namespace/1.0">`)
+}
@@ -1,9 +1,15 @@
 package podcast_test
 
 import (
+	"bytes"
+	"encoding/xml"
 	"fmt"
+	"strconv"
+	"strings"
+	"time"
 
 	podcast "github.com/podpalinc/rss-feed-generator"
+	ext "github.com/podpalinc/rss-feed-generator/parser/extensions"
 )
 
 func ExampleNew() {
@@ -15,7 +21,7 @@ func ExampleNew() {
 
 	fmt.Println(p.Title, p.Link, p.Description.Text, p.Language)
 	// Output:
-	// title link description en-us
+	// title link description en-US
 }
 
 func ExamplePodcast_AddAuthor() {
@@ -35,13 +41,313 @@ func ExamplePodcast_AddCategory() {
 	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
 
 	// add the Category
-	p.AddCategory("Bombay", nil)
-	p.AddCategory("American", []string{"Longhair", "Shorthair"})
-	p.AddCategory("Siamese", nil)
+	if err := p.AddCategory("Technology", nil); err != nil {
+		fmt.Println(err)
+	}
+	if err := p.AddCategory("Arts", []string{"Books", "Design"}); err != nil {
+		fmt.Println(err)
+	}
+	if err := p.AddCategory("Bombay", nil); err != nil {
+		fmt.Println(err)
+	}
+
+	fmt.Println(len(p.ICategories), len(p.ICategories[0].ICategories))
+	// Output:
+	// Bombay: unknown Apple Podcasts category
+	// 2 2
+}
+
+func ExamplePodcast_AddAppleCategory() {
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+
+	// add the Category using the typed taxonomy
+	if err := p.AddAppleCategory(podcast.CategoryNews, podcast.CategoryNewsTechNews); err != nil {
+		fmt.Println(err)
+	}
+	if err := p.AddAppleCategory(podcast.CategoryArts, podcast.CategoryNewsPolitics); err != nil {
+		fmt.Println(err)
+	}
+
+	for _, c := range p.ICategories {
+		fmt.Println(c.Text, ">", c.ICategories[0].Text)
+	}
+	// Output:
+	// Politics: not a subcategory of Apple Podcasts category Arts
+	// News > Tech News
+}
+
+func ExamplePodcast_AddGooglePlayCategory() {
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+
+	// AddCategory adds the matching Google Play category for you
+	p.AddCategory("True Crime", nil)
+	p.AddCategory("History", nil)
+	p.AddCategory("Fiction", []string{"Drama"})
+
+	for _, c := range p.GooglePlayCategories {
+		fmt.Println(c.Text)
+	}
+	// Output:
+	// Arts
+	// Society & Culture
+}
+
+func ExamplePodcast_AddSpotifyCountryOfOrigin() {
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+
+	// show the 10 most recent episodes to listeners in the US and Canada
+	p.AddSpotifyLimit(10)
+	if err := p.AddSpotifyCountryOfOrigin("US", "CA"); err != nil {
+		fmt.Println(err)
+	}
+
+	fmt.Println(p.SpotifyLimit.RecentCount, p.SpotifyCountryOfOrigin)
+	// Output:
+	// 10 us ca
+}
+
+func ExamplePodcast_Validate() {
+	p := podcast.New("title", "http://example.com/", podcast.Description{Text: "Description"}, nil, nil)
+	p.AddLanguage("en-us")
+	p.AddImage("http://example.com/artwork.png")
+	p.AddAuthor([]string{"Jane Doe"})
+	p.AddParentalAdvisory(podcast.ParentalAdvisoryClean)
+	p.AddCategory("Technology", nil)
+
+	// check the feed before submitting it to Apple Podcasts
+	for _, issue := range p.Validate(podcast.PlatformApple) {
+		fmt.Println(issue)
+	}
+	// Output:
+	// error: apple: channel/itunes:owner/itunes:email: owner email is required
+}
+
+func ExampleItem_AddPubDateTime() {
+	p := podcast.New("title", "http://example.com/", podcast.Description{Text: "Description"}, nil, nil)
+
+	// the typed fields are formatted when the feed is encoded
+	i := podcast.Item{Title: "Episode 1", Description: &podcast.Description{Text: "Description"}}
+	i.AddEnclosure("http://example.com/1.mp3", podcast.MP3, "audio/mpeg", 183)
+	i.AddPubDateTime(time.Date(2021, time.March, 14, 18, 34, 5, 0, time.UTC))
+	i.AddDurationTime(42*time.Minute + 7*time.Second)
+	i.AddExplicit(false)
+	i.AddSeason(2)
+	i.AddEpisode(1)
+	if _, err := p.AddItem(i); err != nil {
+		fmt.Println(err)
+	}
+
+	for _, line := range strings.Split(p.String(), "\n") {
+		for _, tag := range []string{"<pubDate>", "<itunes:duration>", "<itunes:explicit>", "<itunes:season>", "<itunes:episode>"} {
+			if strings.HasPrefix(strings.TrimSpace(line), tag) {
+				fmt.Println(strings.TrimSpace(line))
+			}
+		}
+	}
+	// Output:
+	// <pubDate>Sun, 14 Mar 2021 18:34:05 +0000</pubDate>
+	// <itunes:season>2</itunes:season>
+	// <itunes:episode>1</itunes:episode>
+	// <itunes:duration>42:07</itunes:duration>
+	// <itunes:explicit>no</itunes:explicit>
+}
+
+func ExamplePodcast_UpsertItem() {
+	p := podcast.New("title", "http://example.com/", podcast.Description{Text: "Description"}, nil, nil)
+	p.DuplicateGUIDs = podcast.DuplicateGUIDReject
+
+	i := podcast.Item{Title: "Episode 1", Description: &podcast.Description{Text: "Description"}}
+	i.AddEnclosure("http://example.com/1.mp3", podcast.MP3, "audio/mpeg", 183)
+	if _, err := p.AddItem(i); err != nil {
+		fmt.Println(err)
+	}
 
-	fmt.Println(len(p.ICategories), len(p.ICategories[1].ICategories))
+	// the episode was re-uploaded: AddItem rejects it, UpsertItem replaces it
+	i.AddEnclosure("http://example.com/1.mp3", podcast.MP3, "audio/mpeg", 190)
+	if _, err := p.AddItem(i); err != nil {
+		fmt.Println(err)
+	}
+	if _, err := p.UpsertItem(i); err != nil {
+		fmt.Println(err)
+	}
+
+	fmt.Println(len(p.Items), p.FindItem("http://example.com/1.mp3").Enclosure.LengthFormatted)
 	// Output:
-	// 3 2
+	// http://example.com/1.mp3: duplicate Item GUID
+	// 1 190
+}
+
+func ExamplePodcast_EncodeWithOptions() {
+	p := podcast.New("title", "http://example.com/", podcast.Description{Text: "Description"}, nil, nil)
+	now := time.Date(2021, time.March, 14, 12, 0, 0, 0, time.UTC)
+
+	// prepare next week's episode ahead of time
+	i := podcast.Item{Title: "Episode 2", Description: &podcast.Description{Text: "Description"}}
+	i.AddEnclosure("http://example.com/2.mp3", podcast.MP3, "audio/mpeg", 183)
+	i.AddScheduledPubDate(now.AddDate(0, 0, 7))
+	if _, err := p.AddItem(i); err != nil {
+		fmt.Println(err)
+	}
+
+	for _, day := range []int{6, 7} {
+		var b bytes.Buffer
+		if err := p.EncodeWithOptions(&b, podcast.EncodeOptions{
+			Clock: func() time.Time { return now.AddDate(0, 0, day) },
+		}); err != nil {
+			fmt.Println(err)
+		}
+		fmt.Println(day, strings.Contains(b.String(), "<title>Episode 2</title>"))
+	}
+	// Output:
+	// 6 false
+	// 7 true
+}
+
+func ExamplePodcast_Pages() {
+	p := podcast.New("title", "http://example.com/", podcast.Description{Text: "Description"}, nil, nil)
+	p.AddAtomLink("http://example.com/feed.xml")
+	start := time.Date(2021, time.January, 4, 0, 0, 0, 0, time.UTC)
+
+	// a weekly show with a long back catalog
+	for n := 1; n <= 50; n++ {
+		i := podcast.Item{Title: "Episode " + strconv.Itoa(n), Description: &podcast.Description{Text: "Description"}}
+		i.AddEnclosure("http://example.com/"+strconv.Itoa(n)+".mp3", podcast.MP3, "audio/mpeg", 183)
+		i.AddPubDateTime(start.AddDate(0, 0, 7*n))
+		if _, err := p.AddItem(i); err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	pages, err := p.Pages(podcast.PageOptions{
+		PageSize:      20,
+		URLTemplate:   "http://example.com/archive/{page}.xml",
+		EncodeOptions: podcast.EncodeOptions{Order: podcast.ItemOrderNewestFirst},
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+	for _, pg := range pages {
+		var b bytes.Buffer
+		if err := pg.Encode(&b); err != nil {
+			fmt.Println(err)
+		}
+		fmt.Println(pg.URL, len(pg.Items), pg.Items[0].Title)
+	}
+	// Output:
+	// http://example.com/feed.xml 30 Episode 50
+	// http://example.com/archive/1.xml 20 Episode 20
+}
+
+func ExamplePodcast_AddExtension() {
+	p := podcast.New("title", "http://example.com/", podcast.Description{Text: "Description"}, nil, nil)
+
+	// declare the namespace once, then add its elements to the channel or items
+	if err := p.AddNamespace("dc", "http://purl.org/dc/elements/1.1/"); err != nil {
+		fmt.Println(err)
+	}
+	if err := p.AddExtension("dc", ext.Extension{Name: "creator", Value: "Jane Doe"}); err != nil {
+		fmt.Println(err)
+	}
+
+	for _, line := range strings.Split(p.String(), "\n") {
+		if strings.Contains(line, "dc:") {
+			fmt.Println(strings.TrimSpace(line))
+		}
+	}
+	// Output:
+	// <dc:creator>Jane Doe</dc:creator>
+}
+
+func ExamplePodcast_AddPerson() {
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+
+	// credit the hosts and the producer
+	people := []podcast.Person{
+		{Name: "Jane Doe", Role: "Host", Img: "http://example.com/jane.jpg"},
+		{Name: "John Doe", Role: podcast.PersonRoleCoHost, Group: podcast.PersonGroupCast},
+		{Name: "Alice", Role: podcast.PersonRoleProducer, Href: "http://example.com/alice"},
+	}
+	for _, person := range people {
+		if err := p.AddPerson(person); err != nil {
+			fmt.Println(err)
+		}
+	}
+
+	out, _ := xml.MarshalIndent(p.Persons, "", "  ")
+	fmt.Println(string(out))
+	fmt.Println(p.IAuthor)
+	// Output:
+	// <podcast:person role="host" img="http://example.com/jane.jpg">Jane Doe</podcast:person>
+	// <podcast:person role="co-host" group="cast">John Doe</podcast:person>
+	// <podcast:person role="producer" href="http://example.com/alice">Alice</podcast:person>
+	// Jane Doe, John Doe
+}
+
+func ExampleItem_AddPerson() {
+	i := podcast.Item{
+		Title:       "item title",
+		Description: &podcast.Description{Text: "item desc"},
+		Link:        "item link",
+	}
+
+	// a guest host takes over this episode
+	if err := i.AddPerson(podcast.Person{Name: "Sam", Role: podcast.PersonRoleHost}); err != nil {
+		fmt.Println(err)
+	}
+	if err := i.AddPerson(podcast.Person{Name: "Lee", Role: podcast.PersonRoleGuest}); err != nil {
+		fmt.Println(err)
+	}
+
+	fmt.Println(len(i.Persons), i.IAuthor)
+	// Output:
+	// 2 Sam
+}
+
+func ExamplePodcast_AddValue() {
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+	p.AddFunding("https://example.com/donate", "Support the show!")
+
+	// split payments 90/10 between the host and the producer, after a 1% fee
+	err := p.AddValue(podcast.Value{
+		Type:      podcast.ValueTypeLightning,
+		Method:    podcast.ValueMethodKeysend,
+		Suggested: "0.00000005000",
+		Recipients: []*podcast.ValueRecipient{
+			{Name: "Host", Type: podcast.RecipientTypeNode, Address: "02d5c1bf8b940dc9cadca86d1b0a3c37fbe39cee4c7e839e33bef9174531d27f52", Split: 90},
+			{Name: "Producer", Type: podcast.RecipientTypeLNAddress, Address: "producer@example.com", Split: 10},
+			{Name: "Hosting", Type: podcast.RecipientTypeNode, Address: "03ae9f91a0cb8ff43840e3c322c4c61f019d8c1c3cea15a25cfc425ac605e61a4a", Split: 1, Fee: true, CustomKey: "696969", CustomValue: "eChoVKtO1KujpAA5HCoB"},
+		},
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	out, _ := xml.MarshalIndent(p.Funding, "", "  ")
+	fmt.Println(string(out))
+	out, _ = xml.MarshalIndent(p.Values, "", "  ")
+	fmt.Println(string(out))
+	// Output:
+	// <podcast:funding url="https://example.com/donate">Support the show!</podcast:funding>
+	// <podcast:value type="lightning" method="keysend" suggested="0.00000005000">
+	//   <podcast:valueRecipient name="Host" type="node" address="02d5c1bf8b940dc9cadca86d1b0a3c37fbe39cee4c7e839e33bef9174531d27f52" split="90"></podcast:valueRecipient>
+	//   <podcast:valueRecipient name="Producer" type="lnaddress" address="producer@example.com" split="10"></podcast:valueRecipient>
+	//   <podcast:valueRecipient name="Hosting" customKey="696969" customValue="eChoVKtO1KujpAA5HCoB" type="node" address="03ae9f91a0cb8ff43840e3c322c4c61f019d8c1c3cea15a25cfc425ac605e61a4a" split="1" fee="true"></podcast:valueRecipient>
+	// </podcast:value>
+}
+
+func ExamplePodcast_AddLocked() {
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+
+	// lock the feed and set its permanent GUID from the original feed url
+	p.AddLocked(true, "owner@example.com")
+	p.AddGUID(podcast.GenerateFeedGUID("https://mp3s.nashownotes.com/pc20rss.xml"))
+
+	out, _ := xml.Marshal(p.Locked)
+	fmt.Println(string(out))
+	fmt.Println(p.GUID)
+	// Output:
+	// <podcast:locked owner="owner@example.com">yes</podcast:locked>
+	// 917393e3-1b1e-5cef-ace4-edaa54e1f810
 }
 
 func ExamplePodcast_AddImage() {
@@ -234,3 +540,134 @@ func ExampleItem_AddDuration() {
 	// Output:
 	// 533
 }
+
+func ExampleItem_AddTranscript() {
+	i := podcast.Item{
+		Title:       "item title",
+		Description: &podcast.Description{Text: "item desc"},
+		Link:        "item link",
+	}
+
+	// add a captions file and a plain HTML transcript
+	if err := i.AddTranscript("http://example.com/1.vtt", podcast.TranscriptTypeVTT, "en", true); err != nil {
+		fmt.Println(err)
+	}
+	if err := i.AddTranscript("http://example.com/1.html", podcast.TranscriptTypeHTML, "en", false); err != nil {
+		fmt.Println(err)
+	}
+
+	out, _ := xml.Marshal(i.Transcripts)
+	fmt.Println(string(out))
+	fmt.Println(i.IIsClosedCaptioned)
+	// Output:
+	// <podcast:transcript url="http://example.com/1.vtt" type="text/vtt" language="en" rel="captions"></podcast:transcript><podcast:transcript url="http://example.com/1.html" type="text/html" language="en"></podcast:transcript>
+	// Yes
+}
+
+func ExampleItem_AddSoundbite() {
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+	i := podcast.Item{
+		Title:       "item title",
+		Description: &podcast.Description{Text: "item desc"},
+		Link:        "http://example.com/1.html",
+	}
+	i.AddDuration(1800)
+	i.AddChapters("http://example.com/1.chapters.json", podcast.ChaptersTypeJSON)
+
+	// add a 45.5 second clip starting at 1:13
+	if err := i.AddSoundbite(73*time.Second, 45500*time.Millisecond, "The best part"); err != nil {
+		fmt.Println(err)
+	}
+	if _, err := p.AddItem(i); err != nil {
+		fmt.Println(err)
+	}
+
+	out, _ := xml.Marshal(p.Items[0].Chapters)
+	fmt.Println(string(out))
+	out, _ = xml.Marshal(p.Items[0].Soundbites)
+	fmt.Println(string(out))
+	// Output:
+	// <podcast:chapters url="http://example.com/1.chapters.json" type="application/json+chapters"></podcast:chapters>
+	// <podcast:soundbite startTime="73" duration="45.5">The best part</podcast:soundbite>
+}
+
+func ExampleItem_AddAlternateEnclosure() {
+	i := podcast.Item{
+		Title:       "item title",
+		Description: &podcast.Description{Text: "item desc"},
+	}
+	i.AddEnclosure("https://example.com/1.mp3", podcast.MP3, "audio/mpeg", 43200000)
+
+	// checksum the Opus file so apps can verify it came from the show
+	integrity, err := podcast.NewIntegritySRI(strings.NewReader("opus media"), "sha256")
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	err = i.AddAlternateEnclosure(podcast.AlternateEnclosure{
+		Type:      "audio/opus",
+		Length:    32400000,
+		Bitrate:   96000,
+		Title:     "High quality",
+		Integrity: integrity,
+		Sources: []*podcast.Source{
+			{URI: "https://example.com/1.opus"},
+			{URI: "ipfs://someRandomOpusFile", ContentType: "audio/opus"},
+		},
+	})
+	if err != nil {
+		fmt.Println(err)
+	}
+
+	out, _ := xml.MarshalIndent(i.AlternateEnclosures, "", "  ")
+	fmt.Println(string(out))
+	// Output:
+	// <podcast:alternateEnclosure type="audio/opus" length="32400000" bitrate="96000" title="High quality">
+	//   <podcast:source uri="https://example.com/1.opus"></podcast:source>
+	//   <podcast:source uri="ipfs://someRandomOpusFile" contentType="audio/opus"></podcast:source>
+	//   <podcast:integrity type="sri" value="sha256-ETqYJkpjAykZ3FIiKvE+L+wcFN84eZqNqQFG9E7mwW4="></podcast:integrity>
+	// </podcast:alternateEnclosure>
+}
+
+func ExamplePodcast_AddLiveItem() {
+	p := podcast.New("title", "http://example.com/", podcast.Description{Text: "Description"}, nil, nil)
+	start := time.Date(2021, time.September, 26, 7, 30, 0, 0, time.UTC)
+
+	// announce next week's live show
+	li := podcast.LiveItem{
+		Status: podcast.LiveItemStatusPending,
+		Start:  start,
+		End:    start.Add(2 * time.Hour),
+	}
+	li.AddTitle("Live Episode 1")
+	li.AddGUID("live-1")
+	li.AddEnclosure("https://example.com/live.mp3", podcast.MP3, "audio/mpeg", 0)
+	li.AddContentLink("https://youtube.com/example-live", "Watch on YouTube")
+	if _, err := p.AddLiveItem(li); err != nil {
+		fmt.Println(err)
+	}
+
+	var b bytes.Buffer
+	e := xml.NewEncoder(&b)
+	e.Indent("", "  ")
+	if err := e.Encode(p.LiveItems); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(b.String())
+
+	// later, the recording is published as a regular episode
+	recording := podcast.Enclosure{URL: "https://example.com/1.mp3", Type: podcast.MP3, Length: 1234}
+	if _, err := p.PublishLiveItem("live-1", recording); err != nil {
+		fmt.Println(err)
+	}
+	fmt.Println(len(p.LiveItems), p.Items[0].GUID.Value, p.Items[0].Link, p.Items[0].PubDate)
+	// Output:
+	// <podcast:liveItem status="pending" start="2021-09-26T07:30:00Z" end="2021-09-26T09:30:00Z">
+	//   <guid isPermaLink="false">live-1</guid>
+	//   <title>Live Episode 1</title>
+	//   <link>https://example.com/live.mp3</link>
+	//   <enclosure url="https://example.com/live.mp3" length="0" type="audio/mpeg"></enclosure>
+	//   <podcast:contentLink href="https://youtube.com/example-live">Watch on YouTube</podcast:contentLink>
+	// </podcast:liveItem>
+	// 0 live-1 https://example.com/1.mp3 Sun, 26 Sep 2021 07:30:00 +0000
+}
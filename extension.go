@@ -0,0 +1,245 @@
+package podcast
+
+import (
+	"encoding/xml"
+	"sort"
+	"strings"
+
+	"github.com/pkg/errors"
+	ext "github.com/podpalinc/rss-feed-generator/parser/extensions"
+)
+
+// Extension is an element of a namespace this package does not model,
+// written after the built-in fields of the Podcast or Item it belongs to.
+//
+// The element itself is the ext.Extension the parser package reads, so the
+// extensions of a parsed feed can be written back with NewExtensions.
+type Extension struct {
+	// Prefix of the namespace of the element, declared with
+	// Podcast.AddNamespace unless it is one of the built-in namespaces.
+	Prefix string
+
+	// Name, Value, Attrs and Children of the element.  The Attrs and the
+	// Children are written sorted by name, the Children in the namespace
+	// of the element unless their Name has a prefix of its own.
+	ext.Extension
+
+	// CDATA writes the Value in a CDATA section rather than escaped.  It is
+	// not restored by Decode.
+	CDATA bool
+}
+
+// builtinNamespaces are the prefixes NewWrapper always declares.
+var builtinNamespaces = map[string]string{
+	"atom":       ATOMNS,
+	"itunes":     ITUNESNS,
+	"googleplay": GOOGLEPLAYNS,
+	"spotify":    SPOTIFYNS,
+	"content":    CONTENT,
+	"podcast":    PODCASTNS,
+}
+
+// AddNamespace declares the namespace of the prefix on the rss element, for
+// the Extensions of the Podcast and its Items to use.
+func (p *Podcast) AddNamespace(prefix, uri string) error {
+	if len(prefix) == 0 || strings.ContainsAny(prefix, ": \t\r\n") ||
+		strings.HasPrefix(strings.ToLower(prefix), "xml") {
+		return errors.New(prefix + ": invalid namespace prefix")
+	}
+	if _, ok := builtinNamespaces[prefix]; ok || prefix == "fh" && uri != FHNS {
+		return errors.New(prefix + ": namespace is built-in")
+	}
+	if len(uri) == 0 {
+		return errors.New(prefix + ": namespace URI is required")
+	}
+	if p.Namespaces == nil {
+		p.Namespaces = map[string]string{}
+	}
+	p.Namespaces[prefix] = uri
+	return nil
+}
+
+// AddExtension adds the element of the namespace of the prefix to the
+// Podcast, after its built-in fields.  The namespace must be built-in or
+// declared with AddNamespace first.
+func (p *Podcast) AddExtension(prefix string, e ext.Extension) error {
+	if _, ok := builtinNamespaces[prefix]; !ok && len(p.Namespaces[prefix]) == 0 {
+		return errors.New(prefix + ": namespace is not declared, see AddNamespace")
+	}
+	x, err := newExtension(prefix, e)
+	if err != nil {
+		return err
+	}
+	p.Extensions = append(p.Extensions, x)
+	return nil
+}
+
+// AddExtension adds the element of the namespace of the prefix to the Item,
+// after its built-in fields.  The namespace must be built-in or declared
+// with Podcast.AddNamespace.
+func (i *Item) AddExtension(prefix string, e ext.Extension) error {
+	x, err := newExtension(prefix, e)
+	if err != nil {
+		return err
+	}
+	i.Extensions = append(i.Extensions, x)
+	return nil
+}
+
+func newExtension(prefix string, e ext.Extension) (*Extension, error) {
+	if len(prefix) == 0 {
+		return nil, errors.New("Extension prefix is required")
+	}
+	if len(e.Name) == 0 {
+		return nil, errors.New(prefix + ": Extension.Name is required")
+	}
+	return &Extension{Prefix: prefix, Extension: e}, nil
+}
+
+// NewExtensions returns the Extensions of the ext.Extensions read by the
+// parser package, sorted by prefix and name.
+func NewExtensions(extensions ext.Extensions) []*Extension {
+	var xs []*Extension
+	for _, prefix := range sortedKeys(extensions) {
+		elements := extensions[prefix]
+		names := make([]string, 0, len(elements))
+		for name := range elements {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			for _, e := range elements[name] {
+				xs = append(xs, &Extension{Prefix: prefix, Extension: e})
+			}
+		}
+	}
+	return xs
+}
+
+func sortedKeys(extensions ext.Extensions) []string {
+	keys := make([]string, 0, len(extensions))
+	for k := range extensions {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// qualifiedName returns the prefixed name of the element.
+func qualifiedName(prefix, name string) string {
+	if len(prefix) == 0 || strings.Contains(name, ":") {
+		return name
+	}
+	return prefix + ":" + name
+}
+
+// sortedAttrs returns the attributes sorted by name.
+func sortedAttrs(attrs map[string]string) []xml.Attr {
+	sorted := make([]xml.Attr, 0, len(attrs))
+	for name, value := range attrs {
+		sorted = append(sorted, xml.Attr{Name: xml.Name{Local: name}, Value: value})
+	}
+	sort.Slice(sorted, func(a, b int) bool {
+		return sorted[a].Name.Local < sorted[b].Name.Local
+	})
+	return sorted
+}
+
+// sortedChildren returns the children elements sorted by name.
+func sortedChildren(children map[string][]ext.Extension) []ext.Extension {
+	names := make([]string, 0, len(children))
+	for name := range children {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sorted []ext.Extension
+	for _, name := range names {
+		sorted = append(sorted, children[name]...)
+	}
+	return sorted
+}
+
+// extensionElement is the form encoding/xml marshals an Extension in.
+type extensionElement struct {
+	XMLName  xml.Name
+	Attrs    []xml.Attr          `xml:",any,attr"`
+	Text     string              `xml:",chardata"`
+	CDATA    string              `xml:",cdata"`
+	Children []*extensionElement `xml:",any"`
+}
+
+func newExtensionElement(prefix string, e ext.Extension, cdata bool) *extensionElement {
+	el := &extensionElement{
+		XMLName: xml.Name{Local: qualifiedName(prefix, e.Name)},
+		Attrs:   sortedAttrs(e.Attrs),
+	}
+	if cdata {
+		el.CDATA = e.Value
+	} else {
+		el.Text = e.Value
+	}
+	for _, c := range sortedChildren(e.Children) {
+		if len(c.Name) > 0 {
+			el.Children = append(el.Children, newExtensionElement(prefix, c, false))
+		}
+	}
+	return el
+}
+
+// MarshalXML implements xml.Marshaler.  An Extension without a Name is not
+// written.
+func (x Extension) MarshalXML(e *xml.Encoder, start xml.StartElement) error {
+	if len(x.Name) == 0 {
+		return nil
+	}
+	return e.Encode(newExtensionElement(x.Prefix, x.Extension, x.CDATA))
+}
+
+// UnmarshalXML implements xml.Unmarshaler, reading an element of a
+// namespace this package does not model.
+func (x *Extension) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	if n := strings.Index(start.Name.Local, ":"); n >= 0 {
+		x.Prefix = start.Name.Local[:n]
+	}
+	e, err := unmarshalExtension(d, start, x.Prefix)
+	x.Extension = e
+	return err
+}
+
+// unmarshalExtension reads the element up to its end, dropping the prefix
+// of the namespace from the names.
+func unmarshalExtension(d *xml.Decoder, start xml.StartElement, prefix string) (ext.Extension, error) {
+	e := ext.Extension{
+		Name:     strings.TrimPrefix(start.Name.Local, prefix+":"),
+		Attrs:    map[string]string{},
+		Children: map[string][]ext.Extension{},
+	}
+	if len(prefix) == 0 {
+		e.Name = start.Name.Local
+	}
+	for _, a := range start.Attr {
+		e.Attrs[a.Name.Local] = a.Value
+	}
+
+	var value strings.Builder
+	for {
+		t, err := d.Token()
+		if err != nil {
+			return e, err
+		}
+		switch tt := t.(type) {
+		case xml.StartElement:
+			c, err := unmarshalExtension(d, tt, prefix)
+			if err != nil {
+				return e, err
+			}
+			e.Children[c.Name] = append(e.Children[c.Name], c)
+		case xml.CharData:
+			value.Write(tt)
+		case xml.EndElement:
+			e.Value = strings.TrimSpace(value.String())
+			return e, nil
+		}
+	}
+}
@@ -0,0 +1,178 @@
+package podcast_test
+
+import (
+	"bytes"
+	"testing"
+
+	podcast "github.com/podpalinc/rss-feed-generator"
+	ext "github.com/podpalinc/rss-feed-generator/parser/extensions"
+	"github.com/stretchr/testify/assert"
+)
+
+func newExtensionTestPodcast(t *testing.T) podcast.Podcast {
+	p := podcast.New("title", "http://example.com/", podcast.Description{Text: "Description"}, nil, nil)
+	if err := p.AddNamespace("media", "http://search.yahoo.com/mrss/"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddNamespace("dc", "http://purl.org/dc/elements/1.1/"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddExtension("dc", ext.Extension{Name: "creator", Value: "Jane & John"}); err != nil {
+		t.Fatal(err)
+	}
+
+	i := podcast.Item{Title: "Episode 1", Description: &podcast.Description{Text: "Description"}}
+	i.AddEnclosure("http://example.com/1.mp3", podcast.MP3, "", 183)
+	if err := i.AddExtension("media", ext.Extension{
+		Name:  "content",
+		Attrs: map[string]string{"url": "http://example.com/1.mp4", "medium": "video"},
+		Children: map[string][]ext.Extension{
+			"title":     {{Name: "title", Value: "Episode 1"}},
+			"thumbnail": {{Name: "thumbnail", Attrs: map[string]string{"url": "http://example.com/1.png"}}},
+		},
+	}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := p.AddItem(i); err != nil {
+		t.Fatal(err)
+	}
+	return p
+}
+
+func TestExtensionsEncode(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newExtensionTestPodcast(t)
+
+	// act
+	out := p.String()
+
+	// assert
+	assert.Contains(t, out, `xmlns:podcast="https://podcastindex.org/namespace/1.0" xmlns:dc="http://purl.org/dc/elements/1.1/" xmlns:media="http://search.yahoo.com/mrss/">`)
+	assert.Contains(t, out, `<dc:creator>Jane &amp; John</dc:creator>
+    <item>`)
+	assert.Contains(t, out, `<enclosure url="http://example.com/1.mp3" length="183" type="audio/mpeg"></enclosure>
+      <media:content medium="video" url="http://example.com/1.mp4">
+        <media:thumbnail url="http://example.com/1.png"></media:thumbnail>
+        <media:title>Episode 1</media:title>
+      </media:content>
+    </item>`)
+}
+
+func TestExtensionsCDATA(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("title", "http://example.com/", podcast.Description{Text: "Description"}, nil, nil)
+	p.Extensions = append(p.Extensions, &podcast.Extension{
+		Prefix:    "content",
+		Extension: ext.Extension{Name: "encoded", Value: "<p>Show notes</p>"},
+		CDATA:     true,
+	})
+
+	// act
+	out := p.String()
+
+	// assert
+	assert.Contains(t, out, `<content:encoded><![CDATA[<p>Show notes</p>]]></content:encoded>`)
+}
+
+func TestExtensionsDecodeRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newExtensionTestPodcast(t)
+	var want, got bytes.Buffer
+	if err := p.Encode(&want); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	decoded, err := podcast.Decode(bytes.NewReader(want.Bytes()))
+	if !assert.NoError(t, err) {
+		return
+	}
+	err = decoded.Encode(&got)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, want.String(), got.String())
+	assert.Equal(t, "http://search.yahoo.com/mrss/", decoded.Namespaces["media"])
+	if assert.Len(t, decoded.Items[0].Extensions, 1) {
+		x := decoded.Items[0].Extensions[0]
+		assert.Equal(t, "media", x.Prefix)
+		assert.Equal(t, "content", x.Name)
+		assert.Equal(t, "video", x.Attrs["medium"])
+		assert.Equal(t, "Episode 1", x.Children["title"][0].Value)
+	}
+}
+
+func TestExtensionsErrors(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("title", "http://example.com/", podcast.Description{Text: "Description"}, nil, nil)
+	i := podcast.Item{}
+
+	// act
+	errInvalid := p.AddNamespace("a:b", "http://example.com/ns")
+	errBuiltin := p.AddNamespace("itunes", "http://example.com/ns")
+	errURI := p.AddNamespace("media", "")
+	errUndeclared := p.AddExtension("media", ext.Extension{Name: "content"})
+	errBuiltinExtension := p.AddExtension("itunes", ext.Extension{Name: "keywords", Value: "go"})
+	errName := i.AddExtension("media", ext.Extension{})
+	errPrefix := i.AddExtension("", ext.Extension{Name: "content"})
+
+	// assert
+	assert.EqualError(t, errInvalid, "a:b: invalid namespace prefix")
+	assert.EqualError(t, errBuiltin, "itunes: namespace is built-in")
+	assert.EqualError(t, errURI, "media: namespace URI is required")
+	assert.EqualError(t, errUndeclared, "media: namespace is not declared, see AddNamespace")
+	assert.NoError(t, errBuiltinExtension)
+	assert.EqualError(t, errName, "media: Extension.Name is required")
+	assert.EqualError(t, errPrefix, "Extension prefix is required")
+}
+
+func TestExtensionsValidate(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newExtensionTestPodcast(t)
+	p.Namespaces = nil
+
+	// act
+	var issues []string
+	for _, issue := range p.Validate(podcast.PlatformRSS) {
+		issues = append(issues, issue.String())
+	}
+
+	// assert
+	assert.Contains(t, issues, "error: rss: channel/dc:creator: namespace dc is not declared")
+	assert.Contains(t, issues, "error: rss: channel/item[0]/media:content: namespace media is not declared")
+}
+
+func TestNewExtensions(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	parsed := ext.Extensions{
+		"media": {
+			"title":   {{Name: "title", Value: "a"}},
+			"content": {{Name: "content", Value: "b"}, {Name: "content", Value: "c"}},
+		},
+		"dc": {
+			"creator": {{Name: "creator", Value: "d"}},
+		},
+	}
+
+	// act
+	xs := podcast.NewExtensions(parsed)
+
+	// assert
+	var got []string
+	for _, x := range xs {
+		got = append(got, x.Prefix+":"+x.Name+"="+x.Value)
+	}
+	assert.Equal(t, []string{"dc:creator=d", "media:content=b", "media:content=c", "media:title=a"}, got)
+}
@@ -0,0 +1,38 @@
+package podcast
+
+import (
+	"crypto/sha1"
+	"fmt"
+	"strings"
+)
+
+// feedGUIDNamespace is the UUID namespace the podcast:guid tag is generated
+// in: ead4c236-bf58-58c6-a2c6-a6b28d128cb6.
+var feedGUIDNamespace = []byte{
+	0xea, 0xd4, 0xc2, 0x36, 0xbf, 0x58, 0x58, 0xc6,
+	0xa2, 0xc6, 0xa6, 0xb2, 0x8d, 0x12, 0x8c, 0xb6,
+}
+
+// GenerateFeedGUID returns the podcast:guid for the feed url, a UUIDv5 of
+// the url with its scheme and trailing slashes stripped.
+//
+// For example, https://mp3s.nashownotes.com/pc20rss.xml gives
+// 917393e3-1b1e-5cef-ace4-edaa54e1f810.
+//
+// Specification: https://github.com/Podcastindex-org/podcast-namespace/blob/main/docs/1.0.md#guid
+func GenerateFeedGUID(feedURL string) string {
+	name := strings.TrimSpace(feedURL)
+	if n := strings.Index(name, "://"); n >= 0 {
+		name = name[n+3:]
+	}
+	name = strings.TrimRight(name, "/")
+
+	h := sha1.New()
+	h.Write(feedGUIDNamespace)
+	h.Write([]byte(name))
+	u := h.Sum(nil)[:16]
+	u[6] = (u[6] & 0x0f) | 0x50 // version 5
+	u[8] = (u[8] & 0x3f) | 0x80 // RFC 4122 variant
+
+	return fmt.Sprintf("%x-%x-%x-%x-%x", u[0:4], u[4:6], u[6:8], u[8:10], u[10:16])
+}
@@ -0,0 +1,27 @@
+package podcast
+
+import "encoding/xml"
+
+// Funding represents a podcast:funding tag linking to a page where
+// listeners can support the show, e.g. a donation or membership page.
+//
+// Specification: https://github.com/Podcastindex-org/podcast-namespace/blob/main/docs/1.0.md#funding
+type Funding struct {
+	XMLName xml.Name `xml:"podcast:funding"`
+	URL     string   `xml:"url,attr"`
+	Text    string   `xml:",chardata"`
+}
+
+// newFunding returns the Funding for the url, or nil when the url is empty.
+//
+// The text is the call to action shown by apps and is limited to 128
+// characters.
+func newFunding(url, text string) *Funding {
+	if len(url) == 0 {
+		return nil
+	}
+	if r := []rune(text); len(r) > 128 {
+		text = string(r[0:128])
+	}
+	return &Funding{URL: url, Text: text}
+}
@@ -11,7 +11,67 @@ type GooglePlayImage struct {
 }
 
 type GooglePlayCategory struct {
-	XMLName              xml.Name `xml:"googleplay:category"`
-	Text                 string   `xml:"text,attr"`
-	GooglePlayCategories []*GooglePlayCategory
+	XMLName              xml.Name              `xml:"googleplay:category"`
+	Text                 string                `xml:"text,attr"`
+	GooglePlayCategories []*GooglePlayCategory `xml:"googleplay:category"`
+}
+
+// GooglePlayCategories maps the Apple Podcasts categories, current and
+// legacy, to the single tier of categories Google Podcasts supports.
+var GooglePlayCategories = map[string]string{
+	"Arts":                       "Arts",
+	"Business":                   "Business",
+	"Comedy":                     "Comedy",
+	"Education":                  "Education",
+	"Fiction":                    "Arts",
+	"Games & Hobbies":            "Games & Hobbies",
+	"Government":                 "Government & Organizations",
+	"Government & Organizations": "Government & Organizations",
+	"Health":                     "Health",
+	"Health & Fitness":           "Health",
+	"History":                    "Society & Culture",
+	"Kids & Family":              "Kids & Family",
+	"Leisure":                    "Games & Hobbies",
+	"Music":                      "Music",
+	"News":                       "News & Politics",
+	"News & Politics":            "News & Politics",
+	"Religion & Spirituality":    "Religion & Spirituality",
+	"Science":                    "Science & Medicine",
+	"Science & Medicine":         "Science & Medicine",
+	"Society & Culture":          "Society & Culture",
+	"Sports":                     "Sports & Recreation",
+	"Sports & Recreation":        "Sports & Recreation",
+	"Technology":                 "Technology",
+	"True Crime":                 "Society & Culture",
+	"TV & Film":                  "TV & Film",
+}
+
+// googlePlayCategoryOrder is the list of Google Podcasts categories, in the
+// order they are written to the feed.
+var googlePlayCategoryOrder = []string{
+	"Arts", "Business", "Comedy", "Education", "Games & Hobbies",
+	"Government & Organizations", "Health", "Kids & Family", "Music",
+	"News & Politics", "Religion & Spirituality", "Science & Medicine",
+	"Society & Culture", "Sports & Recreation", "Technology", "TV & Film",
+}
+
+// googlePlayCategoryIndex returns the position of the category in
+// googlePlayCategoryOrder, or -1.
+func googlePlayCategoryIndex(category string) int {
+	for n, c := range googlePlayCategoryOrder {
+		if c == category {
+			return n
+		}
+	}
+	return -1
+}
+
+// googlePlayYesNo returns the lower case "yes" or "no" Google Play expects
+// for its explicit and block tags, also written to itunes:explicit as
+// AddParentalAdvisory does.
+func googlePlayYesNo(yes bool) string {
+	if yes {
+		return "yes"
+	}
+	return "no"
 }
@@ -9,3 +9,31 @@ type GUID struct {
 	IsPermaLink bool     `xml:"isPermaLink,attr"`
 	Value       string   `xml:",chardata"`
 }
+
+// UnmarshalXML implements xml.Unmarshaler, reading a guid without an
+// isPermaLink attribute as a permalink, as RSS 2.0 specifies.
+func (g *GUID) UnmarshalXML(d *xml.Decoder, start xml.StartElement) error {
+	type guid GUID
+	v := guid{IsPermaLink: true}
+	if err := d.DecodeElement(&v, &start); err != nil {
+		return err
+	}
+	*g = GUID(v)
+	return nil
+}
+
+// DuplicateGUIDPolicy decides what Podcast.AddItem does with an Item whose
+// GUID is already used by another Item of the Podcast.
+type DuplicateGUIDPolicy int
+
+// Duplicate GUID policies.  DuplicateGUIDAllow is the default and appends
+// the Item regardless, as AddItem always has.
+const (
+	// DuplicateGUIDAllow appends the Item, leaving both in the feed.
+	DuplicateGUIDAllow DuplicateGUIDPolicy = iota
+	// DuplicateGUIDReject returns an error and leaves the feed unchanged.
+	DuplicateGUIDReject
+	// DuplicateGUIDMerge replaces the existing Item, keeping its position,
+	// and fills the empty fields of the Item from the existing one.
+	DuplicateGUIDMerge
+)
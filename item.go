@@ -3,10 +3,15 @@ package podcast
 import (
 	"encoding/xml"
 	"fmt"
+	"os"
 	"strconv"
+	"strings"
+	"time"
 	"unicode/utf8"
 
+	"github.com/pkg/errors"
 	"github.com/podpalinc/rss-feed-generator/html2text"
+	"github.com/podpalinc/rss-feed-generator/probe"
 )
 
 // Item represents a single entry in a podcast.
@@ -23,7 +28,8 @@ import (
 //
 // Recommendations:
 // - Setting the minimal fields sets most of other fields, including iTunes.
-// - Use the Published time.Time setting instead of PubDate.
+// - Use the typed PubDateTime, Duration, Explicit, Season and Episode fields
+//   instead of their strings, they are formatted when the feed is encoded.
 // - Always set an Enclosure.Length, to be nice to your downloaders.
 // - Use Enclosure.Type instead of setting TypeFormatted for valid extensions.
 type Item struct {
@@ -40,6 +46,23 @@ type Item struct {
 	PubDate            string `xml:"pubDate,omitempty"`
 	Enclosure          *Enclosure
 
+	// PubDateTime is the publish time of the Item, which overrides PubDate
+	// in RFC 1123Z format when the feed is encoded.
+	PubDateTime time.Time `xml:"-"`
+	// Duration of the episode, which overrides IDuration in HH:MM:SS format
+	// when the feed is encoded.
+	Duration time.Duration `xml:"-"`
+	// Explicit overrides IExplicit when the feed is encoded, unless nil.
+	Explicit *bool `xml:"-"`
+	// Season and Episode override SeasonNumber and EpisodeNumber when the
+	// feed is encoded, unless zero.
+	Season  int `xml:"-"`
+	Episode int `xml:"-"`
+
+	// State is the lifecycle of the Item, deciding whether it is written to
+	// the feed.  The zero value is ItemStatePublished.
+	State ItemState `xml:"-"`
+
 	// https://help.apple.com/itc/podcasts_connect/#/itcb54353390
 	IAuthor            string `xml:"itunes:author,omitempty"`
 	ITitle             string `xml:"itunes:title,omitempty"`
@@ -54,6 +77,25 @@ type Item struct {
 	IExplicit          string `xml:"itunes:explicit,omitempty"`
 	IIsClosedCaptioned string `xml:"itunes:isClosedCaptioned,omitempty"`
 	IOrder             string `xml:"itunes:order,omitempty"`
+
+	// https://support.google.com/podcast-publishers/answer/9889544?hl=en
+	GooglePlayDescription string `xml:"googleplay:description,omitempty"`
+	GooglePlayExplicit    string `xml:"googleplay:explicit,omitempty"`
+	GooglePlayBlock       string `xml:"googleplay:block,omitempty"`
+
+	// https://github.com/Podcastindex-org/podcast-namespace/blob/main/docs/1.0.md
+	Transcripts []*Transcript `xml:"podcast:transcript"`
+	Chapters    *Chapters
+	Soundbites  []*Soundbite `xml:"podcast:soundbite"`
+	Persons     []*Person    `xml:"podcast:person"`
+	Funding     []*Funding   `xml:"podcast:funding"`
+	Values      []*Value     `xml:"podcast:value"`
+
+	AlternateEnclosures []*AlternateEnclosure `xml:"podcast:alternateEnclosure"`
+
+	// Extensions are the elements of other namespaces, written after the
+	// built-in fields.  See AddExtension.
+	Extensions []*Extension `xml:",any"`
 }
 
 func (i *Item) AddGUID(guid string) {
@@ -92,17 +134,249 @@ func (i *Item) AddDescription(description Description) {
 	i.EncodedDescription = &EncodedContent{
 		Text: description.Text,
 	}
+	i.GooglePlayDescription = description.Text
 }
 
 // AddEnclosure adds the downloadable asset to the podcast Item.
+//
+// Deprecated: the enclosureTypeString is redundant with the enclosureType,
+// use AddEnclosureType, or set the Enclosure.TypeFormatted of an
+// UnknownEnclosureType.
 func (i *Item) AddEnclosure(
 	url string, enclosureType EnclosureType, enclosureTypeString string, lengthInBytes int64) {
+	i.AddEnclosureType(url, enclosureType, lengthInBytes)
+	i.Enclosure.TypeFormatted = enclosureTypeString
+}
+
+// AddEnclosureType adds the downloadable asset to the podcast Item, its MIME
+// type written from the enclosureType.
+func (i *Item) AddEnclosureType(url string, enclosureType EnclosureType, lengthInBytes int64) {
 	i.Enclosure = &Enclosure{
-		URL:           url,
-		Type:          enclosureType,
-		TypeFormatted: enclosureTypeString,
-		Length:        lengthInBytes,
+		URL:    url,
+		Type:   enclosureType,
+		Length: lengthInBytes,
+	}
+}
+
+// AddEnclosureURL adds the downloadable asset to the podcast Item, its type
+// detected from the file extension of the url, see EnclosureTypeFromURL.
+func (i *Item) AddEnclosureURL(url string, lengthInBytes int64) error {
+	t := EnclosureTypeFromURL(url)
+	if t == UnknownEnclosureType {
+		return errors.New(url + ": unknown enclosure type, see AddEnclosureType")
+	}
+	i.AddEnclosureType(url, t, lengthInBytes)
+	return nil
+}
+
+// AddEnclosureFile adds the downloadable asset published at the url from
+// its local file, reading the length, type and duration of the enclosure
+// from the file with the probe package.
+//
+// Files other than MP3 and MP4 get the length of the file and the type
+// detected by DetectEnclosureTypeFile, but no duration.
+func (i *Item) AddEnclosureFile(url, name string) error {
+	info, err := probe.File(name)
+	if errors.Cause(err) == probe.ErrUnsupportedFormat {
+		return i.addEnclosureFileUnprobed(url, name)
+	}
+	if err != nil {
+		return errors.Wrap(err, "podcast.AddEnclosureFile: probe.File returned error")
+	}
+
+	t := EnclosureType(0).GetEnclosureType(info.MIMEType)
+	if t == UnknownEnclosureType {
+		return errors.New(name + ": unknown enclosure type " + info.MIMEType)
+	}
+	i.AddEnclosureType(url, t, info.Length)
+	i.AddDuration(int64((info.Duration + time.Second/2) / time.Second))
+	return nil
+}
+
+func (i *Item) addEnclosureFileUnprobed(url, name string) error {
+	fi, err := os.Stat(name)
+	if err != nil {
+		return errors.Wrap(err, "podcast.AddEnclosureFile: os.Stat returned error")
+	}
+	t, err := DetectEnclosureTypeFile(name)
+	if err != nil {
+		return err
+	}
+	if t == UnknownEnclosureType {
+		return errors.New(name + ": unknown enclosure type, see AddEnclosureType")
+	}
+	i.AddEnclosureType(url, t, fi.Size())
+	return nil
+}
+
+// AddTranscript adds a podcast:transcript to the Item.  An episode may have
+// several transcripts, e.g. one per format or language.
+//
+// The transcriptType must be one of the TranscriptType constants.  Setting
+// captions marks the transcript with rel="captions", which also flags the
+// episode as closed captioned for iTunes.  The optional language is a BCP 47
+// language tag, normalized by ParseLanguage.
+func (i *Item) AddTranscript(url, transcriptType, language string, captions bool) error {
+	if len(url) == 0 {
+		return errors.New("Transcript URL is required")
+	}
+	if !isTranscriptType(transcriptType) {
+		return errors.New(url + ": unsupported transcript type " + transcriptType)
+	}
+	if len(language) > 0 {
+		tag, err := ParseLanguage(language)
+		if err != nil {
+			return errors.Wrap(err, url)
+		}
+		language = tag
+	}
+
+	t := &Transcript{
+		URL:      url,
+		Type:     transcriptType,
+		Language: language,
+	}
+	if captions {
+		t.Rel = TranscriptRelCaptions
+		i.IIsClosedCaptioned = "Yes"
+	}
+	i.Transcripts = append(i.Transcripts, t)
+	return nil
+}
+
+// AddChapters links the Item to its chapters file.  The chaptersType
+// defaults to ChaptersTypeJSON when empty.
+func (i *Item) AddChapters(url, chaptersType string) {
+	if len(url) == 0 {
+		return
+	}
+	if len(chaptersType) == 0 {
+		chaptersType = ChaptersTypeJSON
+	}
+
+	i.Chapters = &Chapters{
+		URL:  url,
+		Type: chaptersType,
+	}
+}
+
+// AddSoundbite adds a podcast:soundbite clip to the Item.  The title is
+// optional.
+//
+// When the episode duration is known from Duration or IDuration, the
+// soundbite must fall within it.  Podcast.AddItem repeats this check for
+// soundbites added before the duration.
+func (i *Item) AddSoundbite(startTime, duration time.Duration, title string) error {
+	s := &Soundbite{
+		StartTime:          startTime,
+		StartTimeFormatted: formatSeconds(startTime),
+		Duration:           duration,
+		DurationFormatted:  formatSeconds(duration),
+		Title:              title,
+	}
+	if err := i.validateSoundbite(s); err != nil {
+		return err
+	}
+
+	i.Soundbites = append(i.Soundbites, s)
+	return nil
+}
+
+// validateSoundbite confirms the soundbite lies within the episode.
+func (i *Item) validateSoundbite(s *Soundbite) error {
+	if s.StartTime < 0 {
+		return errors.New(i.Title + ": Soundbite.StartTime must not be negative")
+	}
+	if s.Duration <= 0 {
+		return errors.New(i.Title + ": Soundbite.Duration is required")
+	}
+	if i.Duration > 0 && s.end() > i.Duration {
+		return errors.New(i.Title + ": Soundbite ends after the episode duration " + parseDuration(int64(i.Duration/time.Second)))
+	}
+	if d, ok := parseITunesDuration(i.IDuration); ok && i.Duration <= 0 && s.end() > d {
+		return errors.New(i.Title + ": Soundbite ends after the episode duration " + i.IDuration)
+	}
+	return nil
+}
+
+// AddPerson credits a person on the Item with a podcast:person tag, e.g. a
+// guest of the episode.
+//
+// The Role and Group must come from the official taxonomy listed in
+// PersonRoles.  Hosts and co-hosts are joined into the episode's IAuthor,
+// overriding the author inherited from the Podcast.
+func (i *Item) AddPerson(person Person) error {
+	if err := normalizePerson(&person); err != nil {
+		return err
+	}
+	i.Persons = append(i.Persons, &person)
+
+	if person.isHost() {
+		i.IAuthor = strings.Join(hostNames(i.Persons), ", ")
 	}
+	return nil
+}
+
+// AddFunding adds a podcast:funding link specific to this episode.
+func (i *Item) AddFunding(url, text string) {
+	if f := newFunding(url, text); f != nil {
+		i.Funding = append(i.Funding, f)
+	}
+}
+
+// AddValue adds a podcast:value block to the Item, overriding the payment
+// splits of the Podcast for this episode, e.g. to pay a guest.
+func (i *Item) AddValue(v Value) error {
+	if err := validateValue(&v); err != nil {
+		return errors.Wrap(err, i.Title)
+	}
+	i.Values = append(i.Values, &v)
+	return nil
+}
+
+// AddAlternateEnclosure offers another version of the episode's media, such
+// as a low bitrate MP3, an Opus encoding or a video, with a podcast:alternateEnclosure.
+//
+// Only one alternate enclosure may be the Default, which is the version
+// matching the Item's Enclosure.  When the Item has no Enclosure,
+// Podcast.AddItem derives it from the Default alternate enclosure's first
+// http(s) source so that RSS 2.0 apps still have a download.
+func (i *Item) AddAlternateEnclosure(a AlternateEnclosure) error {
+	if err := validateAlternateEnclosure(&a); err != nil {
+		return errors.Wrap(err, i.Title)
+	}
+	if a.Default && i.defaultAlternateEnclosure() != nil {
+		return errors.New(i.Title + ": only one AlternateEnclosure can be the Default")
+	}
+
+	i.AlternateEnclosures = append(i.AlternateEnclosures, &a)
+	return nil
+}
+
+// defaultAlternateEnclosure returns the alternate enclosure marked Default.
+func (i *Item) defaultAlternateEnclosure() *AlternateEnclosure {
+	for _, a := range i.AlternateEnclosures {
+		if a.Default {
+			return a
+		}
+	}
+	return nil
+}
+
+// fallbackEnclosure returns the RSS 2.0 Enclosure matching the Default
+// alternate enclosure, or nil if it has no http(s) source or its type is
+// not one of the EnclosureType values.
+func (i *Item) fallbackEnclosure() *Enclosure {
+	a := i.defaultAlternateEnclosure()
+	if a == nil {
+		return nil
+	}
+	s := a.httpSource()
+	t := EnclosureType(0).GetEnclosureType(a.Type)
+	if s == nil || t == UnknownEnclosureType || t.String() != a.Type {
+		return nil
+	}
+	return &Enclosure{URL: s.URI, Length: a.Length, Type: t, TypeFormatted: a.Type}
 }
 
 func (i *Item) AddEpisodeNumber(episodeNumber int64) {
@@ -144,6 +418,7 @@ func (i *Item) AddItunesBlock(block string) {
 	} else {
 		i.IBlock = "No"
 	}
+	i.GooglePlayBlock = googlePlayYesNo(block == "hide")
 }
 
 func (i *Item) AddItunesTitle(title string) {
@@ -157,8 +432,10 @@ func (i *Item) AddItunesTitle(title string) {
 func (i *Item) AddParentalAdvisory(parentalAdvisory string) {
 	if parentalAdvisory == ParentalAdvisoryExplicit {
 		i.IExplicit = "yes"
+		i.GooglePlayExplicit = googlePlayYesNo(true)
 	} else if parentalAdvisory == ParentalAdvisoryClean {
 		i.IExplicit = "no"
+		i.GooglePlayExplicit = googlePlayYesNo(false)
 	}
 
 	return
@@ -173,6 +450,51 @@ func (i *Item) AddPubDate(datetime string) {
 	i.PubDate = datetime
 }
 
+// AddPubDateTime sets the publish time of the Item, formatted as RFC 1123Z
+// when the feed is encoded.
+func (i *Item) AddPubDateTime(datetime time.Time) {
+	if datetime.IsZero() {
+		return
+	}
+
+	i.PubDateTime = datetime
+}
+
+// AddScheduledPubDate schedules the Item to appear in the feed from the
+// publish time, setting its State and PubDateTime.
+func (i *Item) AddScheduledPubDate(datetime time.Time) {
+	if datetime.IsZero() {
+		return
+	}
+
+	i.State = ItemStateScheduled
+	i.PubDateTime = datetime
+}
+
+// AddSeason sets the season number of the Item.
+func (i *Item) AddSeason(season int) {
+	if season <= 0 {
+		return
+	}
+
+	i.Season = season
+}
+
+// AddEpisode sets the episode number of the Item.
+func (i *Item) AddEpisode(episode int) {
+	if episode <= 0 {
+		return
+	}
+
+	i.Episode = episode
+}
+
+// AddExplicit marks the Item as explicit, or clean, overriding the show's
+// parental advisory.  It is written "yes" or "no", as AddParentalAdvisory.
+func (i *Item) AddExplicit(explicit bool) {
+	i.Explicit = &explicit
+}
+
 func (i *Item) AddSeasonNumber(seasonNumber int64) {
 	if seasonNumber <= 0 {
 		return
@@ -206,6 +528,54 @@ func (i *Item) AddDuration(durationInSeconds int64) {
 	i.IDuration = fmt.Sprint(durationInSeconds)
 }
 
+// AddDurationTime sets the Duration of the episode, formatted as HH:MM:SS
+// when the feed is encoded.  Fractions of a second are dropped.
+func (i *Item) AddDurationTime(duration time.Duration) {
+	if duration < time.Second {
+		return
+	}
+	i.Duration = duration
+}
+
+// format sets the string fields from the typed fields that are set.
+func (i *Item) format() {
+	if !i.PubDateTime.IsZero() {
+		i.PubDate = i.PubDateTime.Format(time.RFC1123Z)
+	}
+	if i.Duration >= time.Second {
+		i.IDuration = parseDuration(int64(i.Duration / time.Second))
+	}
+	if i.Explicit != nil {
+		i.IExplicit = googlePlayYesNo(*i.Explicit)
+		i.GooglePlayExplicit = i.IExplicit
+	}
+	if i.Season > 0 {
+		i.SeasonNumber = strconv.Itoa(i.Season)
+	}
+	if i.Episode > 0 {
+		i.EpisodeNumber = strconv.Itoa(i.Episode)
+	}
+}
+
+// parseITunesDuration parses an itunes:duration value, which is either a
+// number of seconds or one of the H:MM:SS, MM:SS forms of parseDuration.
+var parseITunesDuration = func(duration string) (time.Duration, bool) {
+	parts := strings.Split(strings.TrimSpace(duration), ":")
+	if len(parts) > 3 {
+		return 0, false
+	}
+
+	var total time.Duration
+	for _, part := range parts {
+		n, err := strconv.ParseUint(part, 10, 32)
+		if err != nil {
+			return 0, false
+		}
+		total = total*60 + time.Duration(n)
+	}
+	return total * time.Second, true
+}
+
 var parseDuration = func(duration int64) string {
 	h := duration / 3600
 	duration = duration % 3600
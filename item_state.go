@@ -0,0 +1,69 @@
+package podcast
+
+import "time"
+
+// ItemState is the lifecycle state of an Item, deciding whether it is
+// written to the feed.
+type ItemState int
+
+// Item lifecycle states.  The zero value is ItemStatePublished so Items
+// added without a state are always in the feed.
+const (
+	// ItemStatePublished Items are always in the feed.
+	ItemStatePublished ItemState = iota
+	// ItemStateDraft Items are never in the feed.
+	ItemStateDraft
+	// ItemStateScheduled Items are in the feed from their PubDateTime.
+	ItemStateScheduled
+	// ItemStateUnpublished Items were taken down and are no longer in the
+	// feed.
+	ItemStateUnpublished
+)
+
+// String returns the name of the ItemState.
+func (s ItemState) String() string {
+	switch s {
+	case ItemStatePublished:
+		return "published"
+	case ItemStateDraft:
+		return "draft"
+	case ItemStateScheduled:
+		return "scheduled"
+	case ItemStateUnpublished:
+		return "unpublished"
+	}
+	return "unknown"
+}
+
+// Visible reports whether the Item is written to the feed at the time now.
+//
+// A scheduled Item without a PubDateTime is never visible.
+func (i *Item) Visible(now time.Time) bool {
+	switch i.State {
+	case ItemStatePublished:
+		return true
+	case ItemStateScheduled:
+		return !i.PubDateTime.IsZero() && !i.PubDateTime.After(now)
+	}
+	return false
+}
+
+// publishTime returns the PubDateTime of the Item, or its PubDate when it
+// is an RFC 2822 date.
+func (i *Item) publishTime() (time.Time, bool) {
+	if !i.PubDateTime.IsZero() {
+		return i.PubDateTime, true
+	}
+	return parseRFC2822Date(i.PubDate)
+}
+
+// parseRFC2822Date parses the date in the RFC 2822 format of RSS 2.0, with a
+// numeric or named zone.
+func parseRFC2822Date(date string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC1123Z, time.RFC1123} {
+		if t, err := time.Parse(layout, date); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -1,7 +1,9 @@
 package podcast_test
 
 import (
+	"strings"
 	"testing"
+	"time"
 
 	podcast "github.com/podpalinc/rss-feed-generator"
 	"github.com/stretchr/testify/assert"
@@ -425,3 +427,353 @@ func TestItemAddDurationLessThanZero(t *testing.T) {
 	// assert
 	assert.EqualValues(t, "", i.IDuration)
 }
+
+func TestAddTranscriptEmptyURL(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	i := podcast.Item{}
+
+	// act
+	err := i.AddTranscript("", podcast.TranscriptTypeVTT, "en", true)
+
+	// assert
+	assert.Error(t, err)
+	assert.Len(t, i.Transcripts, 0)
+	assert.Len(t, i.IIsClosedCaptioned, 0)
+}
+
+func TestAddTranscriptInvalidType(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	i := podcast.Item{}
+
+	// act
+	err := i.AddTranscript("http://example.com/1.txt", "text/plain", "en", false)
+
+	// assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "unsupported transcript type")
+	assert.Len(t, i.Transcripts, 0)
+}
+
+func TestAddTranscriptNotCaptions(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	i := podcast.Item{}
+
+	// act
+	err := i.AddTranscript("http://example.com/1.html", podcast.TranscriptTypeHTML, "", false)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, i.Transcripts, 1)
+	assert.Len(t, i.Transcripts[0].Rel, 0)
+	assert.Len(t, i.IIsClosedCaptioned, 0)
+}
+
+func TestAddChaptersEmpty(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	i := podcast.Item{}
+
+	// act
+	i.AddChapters("", podcast.ChaptersTypeJSON)
+
+	// assert
+	assert.Nil(t, i.Chapters)
+}
+
+func TestAddChaptersDefaultType(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	i := podcast.Item{}
+
+	// act
+	i.AddChapters("http://example.com/1.json", "")
+
+	// assert
+	assert.Equal(t, podcast.ChaptersTypeJSON, i.Chapters.Type)
+}
+
+func TestAddSoundbiteInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name     string
+		duration string
+		start    time.Duration
+		length   time.Duration
+		err      string
+	}{
+		{"negative start", "", -time.Second, time.Second, "must not be negative"},
+		{"zero duration", "", time.Second, 0, "Duration is required"},
+		{"past seconds duration", "60", 50 * time.Second, 15 * time.Second, "after the episode duration"},
+		{"past clock duration", "1:00:00", time.Hour, time.Second, "after the episode duration"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			i := podcast.Item{Title: "title", IDuration: tt.duration}
+
+			// act
+			err := i.AddSoundbite(tt.start, tt.length, "")
+
+			// assert
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+			assert.Len(t, i.Soundbites, 0)
+		})
+	}
+}
+
+func TestAddSoundbiteUnknownDuration(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	i := podcast.Item{Title: "title"}
+
+	// act
+	err := i.AddSoundbite(2*time.Hour, 30*time.Second, "")
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, i.Soundbites, 1)
+}
+
+func TestItemAddPersonInvalid(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	i := podcast.Item{}
+
+	// act
+	err := i.AddPerson(podcast.Person{Name: "Jane", Role: "mascot"})
+
+	// assert
+	assert.Error(t, err)
+	assert.Len(t, i.Persons, 0)
+}
+
+func TestItemAddPersonGuestKeepsAuthor(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	i := podcast.Item{}
+
+	// act
+	err := i.AddPerson(podcast.Person{Name: "Joe", Role: podcast.PersonRoleGuest, Group: podcast.PersonGroupCast})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, i.Persons, 1)
+	assert.Len(t, i.IAuthor, 0)
+}
+
+func TestItemAddFunding(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	i := podcast.Item{}
+
+	// act
+	i.AddFunding("", "ignored")
+	i.AddFunding("https://example.com/episode-sponsor", "Sponsor this episode")
+
+	// assert
+	assert.Len(t, i.Funding, 1)
+	assert.Equal(t, "Sponsor this episode", i.Funding[0].Text)
+}
+
+func TestItemAddValueInvalid(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	i := podcast.Item{Title: "title"}
+
+	// act
+	err := i.AddValue(podcast.Value{Type: podcast.ValueTypeLightning, Method: podcast.ValueMethodKeysend})
+
+	// assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "title")
+	assert.Len(t, i.Values, 0)
+}
+
+func TestAddAlternateEnclosureInvalid(t *testing.T) {
+	t.Parallel()
+
+	source := []*podcast.Source{{URI: "https://example.com/1.opus"}}
+	tests := []struct {
+		name string
+		a    podcast.AlternateEnclosure
+		err  string
+	}{
+		{"missing type", podcast.AlternateEnclosure{Sources: source}, "Type is required"},
+		{"missing sources", podcast.AlternateEnclosure{Type: "audio/opus"}, "at least one Source"},
+		{"relative source", podcast.AlternateEnclosure{Type: "audio/opus", Sources: []*podcast.Source{{URI: "1.opus"}}}, "absolute URI"},
+		{"bad sri", podcast.AlternateEnclosure{Type: "audio/opus", Sources: source, Integrity: &podcast.Integrity{Type: podcast.IntegrityTypeSRI, Value: "md5-abc"}}, "SRI string"},
+		{"bad pgp", podcast.AlternateEnclosure{Type: "audio/opus", Sources: source, Integrity: &podcast.Integrity{Type: podcast.IntegrityTypePGP, Value: "not base64!"}}, "PGP signature"},
+		{"bad integrity type", podcast.AlternateEnclosure{Type: "audio/opus", Sources: source, Integrity: &podcast.Integrity{Type: "md5", Value: "abc"}}, "unsupported Integrity.Type"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			i := podcast.Item{Title: "title"}
+
+			// act
+			err := i.AddAlternateEnclosure(tt.a)
+
+			// assert
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+			assert.Len(t, i.AlternateEnclosures, 0)
+		})
+	}
+}
+
+func TestAddAlternateEnclosureTwoDefaults(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	i := podcast.Item{Title: "title"}
+	a := podcast.AlternateEnclosure{Type: "audio/mpeg", Default: true, Sources: []*podcast.Source{{URI: "https://example.com/1.mp3"}}}
+	assert.NoError(t, i.AddAlternateEnclosure(a))
+
+	// act
+	err := i.AddAlternateEnclosure(a)
+
+	// assert
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "only one AlternateEnclosure")
+	assert.Len(t, i.AlternateEnclosures, 1)
+}
+
+func TestNewIntegritySRIUnsupportedAlgorithm(t *testing.T) {
+	t.Parallel()
+
+	// act
+	i, err := podcast.NewIntegritySRI(strings.NewReader("media"), "md5")
+
+	// assert
+	assert.Nil(t, i)
+	assert.Error(t, err)
+}
+
+func TestItemTypedFieldsEncode(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+	i := podcast.Item{Title: "title", Description: &podcast.Description{Text: "desc"}}
+	i.AddEnclosure("http://example.com/1.mp3", podcast.MP3, "", 1)
+	i.AddPubDate("yesterday")
+	i.AddPubDateTime(time.Date(2021, time.March, 14, 18, 34, 5, 0, time.FixedZone("EST", -5*3600)))
+	i.AddDuration(10)
+	i.AddDurationTime(time.Hour + 2*time.Minute + 3*time.Second + 400*time.Millisecond)
+	i.AddExplicit(false)
+	i.AddSeason(2)
+	i.AddEpisode(10)
+	if _, err := p.AddItem(i); !assert.NoError(t, err) {
+		return
+	}
+
+	// act
+	out := p.String()
+
+	// assert
+	assert.Contains(t, out, "<pubDate>Sun, 14 Mar 2021 18:34:05 -0500</pubDate>")
+	assert.Contains(t, out, "<itunes:duration>1:02:03</itunes:duration>")
+	assert.Contains(t, out, "<itunes:explicit>no</itunes:explicit>")
+	assert.Contains(t, out, "<googleplay:explicit>no</googleplay:explicit>")
+	assert.Contains(t, out, "<itunes:season>2</itunes:season>")
+	assert.Contains(t, out, "<itunes:episode>10</itunes:episode>")
+	assert.EqualValues(t, "yesterday", p.Items[0].PubDate)
+	assert.EqualValues(t, "10", p.Items[0].IDuration)
+}
+
+func TestItemTypedFieldsInvalid(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	i := podcast.Item{}
+
+	// act
+	i.AddPubDateTime(time.Time{})
+	i.AddDurationTime(time.Millisecond)
+	i.AddSeason(0)
+	i.AddEpisode(-1)
+
+	// assert
+	assert.True(t, i.PubDateTime.IsZero())
+	assert.EqualValues(t, 0, i.Duration)
+	assert.EqualValues(t, 0, i.Season)
+	assert.EqualValues(t, 0, i.Episode)
+	assert.Nil(t, i.Explicit)
+}
+
+func TestAddSoundbiteAfterDurationTime(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	i := podcast.Item{Title: "title", IDuration: "1:00:00"}
+	i.AddDurationTime(time.Minute)
+
+	// act
+	err := i.AddSoundbite(50*time.Second, 20*time.Second, "")
+
+	// assert
+	assert.EqualError(t, err, "title: Soundbite ends after the episode duration 1:00")
+}
+
+func TestItemVisible(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2021, time.March, 14, 12, 0, 0, 0, time.UTC)
+	tests := []struct {
+		state   podcast.ItemState
+		pubDate time.Time
+		visible bool
+	}{
+		{podcast.ItemStatePublished, time.Time{}, true},
+		{podcast.ItemStatePublished, now.Add(time.Hour), true},
+		{podcast.ItemStateDraft, now.Add(-time.Hour), false},
+		{podcast.ItemStateScheduled, now, true},
+		{podcast.ItemStateScheduled, now.Add(time.Second), false},
+		{podcast.ItemStateScheduled, time.Time{}, false},
+		{podcast.ItemStateUnpublished, now.Add(-time.Hour), false},
+	}
+	for _, tt := range tests {
+		i := podcast.Item{State: tt.state, PubDateTime: tt.pubDate}
+
+		assert.Equal(t, tt.visible, i.Visible(now), "%s %s", tt.state, tt.pubDate)
+	}
+}
+
+func TestAddScheduledPubDate(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	at := time.Date(2021, time.March, 14, 12, 0, 0, 0, time.UTC)
+	i := podcast.Item{}
+	empty := podcast.Item{}
+
+	// act
+	i.AddScheduledPubDate(at)
+	empty.AddScheduledPubDate(time.Time{})
+
+	// assert
+	assert.Equal(t, podcast.ItemStateScheduled, i.State)
+	assert.Equal(t, at, i.PubDateTime)
+	assert.Equal(t, podcast.ItemStatePublished, empty.State)
+}
@@ -0,0 +1,26 @@
+package podcast
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+)
+
+// ParseLanguage returns the canonical form of the BCP 47 language tag, such
+// as en-US for en-us or en_US, and he for the deprecated iw, or an error if
+// it is not a well-formed tag.
+//
+// It is the validation AddLanguage, Item.AddTranscript and
+// Item.AddAlternateEnclosure apply to their languages.
+func ParseLanguage(tag string) (string, error) {
+	tag = strings.TrimSpace(tag)
+	t, err := language.Parse(tag)
+	if err != nil {
+		return "", errors.New(tag + ": invalid BCP 47 language tag")
+	}
+	if t == language.Und {
+		return "", errors.New(tag + ": language is undetermined")
+	}
+	return t.String(), nil
+}
@@ -0,0 +1,148 @@
+package podcast_test
+
+import (
+	"testing"
+
+	podcast "github.com/podpalinc/rss-feed-generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLanguage(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		tag  string
+		want string
+	}{
+		{"en", "en"},
+		{"en-us", "en-US"},
+		{"EN_us", "en-US"},
+		{" pt-br ", "pt-BR"},
+		{"zh-hant-tw", "zh-Hant-TW"},
+		{"iw", "he"},
+		{"ji", "yi"},
+		{"mo", "ro-MD"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.tag, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			got, err := podcast.ParseLanguage(tt.tag)
+
+			// assert
+			assert.NoError(t, err)
+			assert.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestParseLanguageInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, tag := range []string{"", "e", "en-", "english", "e1-!!", "und"} {
+		tag := tag
+		t.Run(tag, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			got, err := podcast.ParseLanguage(tag)
+
+			// assert
+			assert.Error(t, err)
+			assert.Len(t, got, 0)
+		})
+	}
+}
+
+func TestAddLanguage(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+
+	// act
+	err := p.AddLanguage("en-gb")
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "en-GB", p.Language)
+}
+
+func TestAddLanguageInvalid(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+	p.AddLanguage("fr")
+
+	// act
+	err := p.AddLanguage("french")
+
+	// assert
+	assert.EqualError(t, err, "french: invalid BCP 47 language tag")
+	assert.Equal(t, "fr", p.Language)
+}
+
+func TestAddTranscriptLanguage(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	i := podcast.Item{}
+
+	// act
+	err := i.AddTranscript("http://example.com/1.vtt", podcast.TranscriptTypeVTT, "es_mx", false)
+	errInvalid := i.AddTranscript("http://example.com/2.vtt", podcast.TranscriptTypeVTT, "spanish", false)
+
+	// assert
+	assert.NoError(t, err)
+	assert.EqualError(t, errInvalid, "http://example.com/2.vtt: spanish: invalid BCP 47 language tag")
+	if assert.Len(t, i.Transcripts, 1) {
+		assert.Equal(t, "es-MX", i.Transcripts[0].Language)
+	}
+}
+
+func TestAddAlternateEnclosureLanguage(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	i := podcast.Item{Title: "title"}
+	a := podcast.AlternateEnclosure{Type: "audio/opus", Sources: []*podcast.Source{{URI: "https://example.com/1.opus"}}}
+
+	// act
+	a.Language = "de-at"
+	err := i.AddAlternateEnclosure(a)
+	a.Language = "german"
+	errInvalid := i.AddAlternateEnclosure(a)
+
+	// assert
+	assert.NoError(t, err)
+	assert.EqualError(t, errInvalid, "title: german: invalid BCP 47 language tag")
+	if assert.Len(t, i.AlternateEnclosures, 1) {
+		assert.Equal(t, "de-AT", i.AlternateEnclosures[0].Language)
+	}
+}
+
+func TestValidateLanguage(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newValidPodcast(t)
+	p.Language = "en-us"
+	p.Items[0].Transcripts = []*podcast.Transcript{{URL: "http://example.com/1.vtt", Type: podcast.TranscriptTypeVTT, Language: "klingon"}}
+	p.Items[0].AlternateEnclosures = []*podcast.AlternateEnclosure{{Type: "audio/opus", Language: "iw"}}
+
+	// act
+	var issues []string
+	for _, issue := range p.Validate(podcast.PlatformRSS) {
+		issues = append(issues, issue.String())
+	}
+
+	// assert
+	assert.Equal(t, []string{
+		"warning: rss: channel/language: en-us should be written en-US",
+		"error: rss: channel/item[0]/podcast:transcript/@language: klingon is not a BCP 47 language tag",
+		"warning: rss: channel/item[0]/podcast:alternateEnclosure/@lang: iw should be written he",
+	}, issues)
+}
@@ -0,0 +1,115 @@
+package podcast
+
+import (
+	"encoding/xml"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// LiveItem statuses.
+const (
+	LiveItemStatusPending = "pending"
+	LiveItemStatusLive    = "live"
+	LiveItemStatusEnded   = "ended"
+)
+
+// LiveItem represents a podcast:liveItem, a live stream whose Enclosure is
+// the stream.  Podcast.PublishLiveItem turns it into a regular episode.
+type LiveItem struct {
+	XMLName xml.Name `xml:"podcast:liveItem"`
+
+	// Status is one of the LiveItemStatus values. (Required)
+	Status string `xml:"status,attr"`
+
+	// Start is the time the stream starts. (Required)
+	Start time.Time `xml:"-"`
+	// StartFormatted is the Start time in ISO 8601 format.
+	//
+	// This field gets overwritten with the API when setting Start.
+	StartFormatted string `xml:"start,attr"`
+
+	// End is the time the stream is expected to end.
+	End time.Time `xml:"-"`
+	// EndFormatted is the End time in ISO 8601 format.
+	//
+	// This field gets overwritten with the API when setting End.
+	EndFormatted string `xml:"end,attr,omitempty"`
+
+	Item
+
+	// ContentLinks point to where the stream can be watched or listened to
+	// outside of podcast apps, e.g. a YouTube live page.
+	ContentLinks []*ContentLink `xml:"podcast:contentLink"`
+}
+
+// ContentLink represents a podcast:contentLink tag.
+type ContentLink struct {
+	XMLName xml.Name `xml:"podcast:contentLink"`
+	Href    string   `xml:"href,attr"`
+	Text    string   `xml:",chardata"`
+}
+
+// AddContentLink adds a link to where the live stream can be found outside
+// of podcast apps.  The text describes the link, e.g. "Watch on YouTube".
+func (li *LiveItem) AddContentLink(href, text string) {
+	if len(href) == 0 {
+		return
+	}
+	li.ContentLinks = append(li.ContentLinks, &ContentLink{Href: href, Text: text})
+}
+
+// ToItem returns the regular Item of the live stream, without the stream
+// Enclosure and AlternateEnclosures, so the recording can be added to it.
+//
+// The PubDate defaults to the Start of the stream.
+func (li *LiveItem) ToItem() Item {
+	i := li.Item
+	i.XMLName = xml.Name{}
+	if li.Enclosure != nil && i.Link == li.Enclosure.URL {
+		i.Link = ""
+	}
+	i.Enclosure = nil
+	i.AlternateEnclosures = nil
+	if len(i.PubDate) == 0 && !li.Start.IsZero() {
+		i.PubDate = li.Start.Format(time.RFC1123Z)
+	}
+	return i
+}
+
+// validateLiveItem confirms the live item has a GUID, a status and a start
+// time, then formats the start and end times.
+func validateLiveItem(li *LiveItem) error {
+	if li.GUID == nil || len(li.GUID.Value) == 0 {
+		return errors.New(li.Title + ": LiveItem.GUID is required")
+	}
+	switch li.Status {
+	case LiveItemStatusPending, LiveItemStatusLive, LiveItemStatusEnded:
+	default:
+		return errors.New(li.Title + ": unknown LiveItem.Status " + li.Status)
+	}
+	if li.Start.IsZero() {
+		return errors.New(li.Title + ": LiveItem.Start is required")
+	}
+	if !li.End.IsZero() && li.End.Before(li.Start) {
+		return errors.New(li.Title + ": LiveItem.End is before LiveItem.Start")
+	}
+	if li.Enclosure == nil && li.fallbackEnclosure() == nil {
+		return errors.New(li.Title + ": LiveItem.Enclosure is required for the stream")
+	}
+
+	li.StartFormatted = li.Start.Format(time.RFC3339)
+	li.EndFormatted = ""
+	if !li.End.IsZero() {
+		li.EndFormatted = li.End.Format(time.RFC3339)
+	}
+	return nil
+}
+
+// decodeLiveItem restores the typed LiveItem fields from the formatted
+// attributes read from the feed.
+func decodeLiveItem(li *LiveItem) {
+	li.Start, _ = time.Parse(time.RFC3339, li.StartFormatted)
+	li.End, _ = time.Parse(time.RFC3339, li.EndFormatted)
+	decodeItem(&li.Item)
+}
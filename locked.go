@@ -0,0 +1,14 @@
+package podcast
+
+import "encoding/xml"
+
+// Locked represents the podcast:locked tag, which tells other podcast hosts
+// whether they may import the feed.  Owner is the email address hosts can
+// use to verify ownership when the feed is locked.
+//
+// Specification: https://github.com/Podcastindex-org/podcast-namespace/blob/main/docs/1.0.md#locked
+type Locked struct {
+	XMLName xml.Name `xml:"podcast:locked"`
+	Owner   string   `xml:"owner,attr,omitempty"`
+	Value   string   `xml:",chardata"`
+}
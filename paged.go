@@ -0,0 +1,205 @@
+package podcast
+
+import (
+	"encoding/xml"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// PagePlaceholder is replaced by the page number in PageOptions.URLTemplate.
+const PagePlaceholder = "{page}"
+
+// PageOptions controls how Podcast.Pages splits the feed.
+type PageOptions struct {
+	// PageSize is the number of Items of each archive page.
+	PageSize int
+
+	// URLTemplate is the url of the archive pages, with PagePlaceholder
+	// standing for the page number, such as
+	// "https://example.com/feed.xml?page={page}".
+	URLTemplate string
+
+	// EncodeOptions of the pages.  MaxItems and MaxBytes are ignored, the
+	// size of the pages being set by PageSize.
+	EncodeOptions
+}
+
+// Page is one document of a feed split by Podcast.Pages.
+type Page struct {
+	// Number of the page, 0 for the current feed and from 1 for the oldest
+	// archive page up.
+	Number int
+
+	// URL the page is published at.
+	URL string
+
+	// Items written to the page, in order.
+	Items []*Item
+
+	channel *pageChannel
+	encode  func(w io.Writer, o interface{}) error
+}
+
+// pageChannel is the channel of a Page.  Its Links take the place of the
+// atom:link of the Podcast.
+type pageChannel struct {
+	XMLName  xml.Name    `xml:"channel"`
+	Links    []*AtomLink `xml:"atom:link"`
+	Complete *fhMarker   `xml:"fh:complete"`
+	Archive  *fhMarker   `xml:"fh:archive"`
+	*Podcast
+}
+
+// pageWrapper is the rss element of a Page, declaring the feed history
+// namespace.
+type pageWrapper struct {
+	PodcastWrapper
+	FHNS    string       `xml:"xmlns:fh,attr"`
+	Channel *pageChannel `xml:"channel"`
+}
+
+// fhMarker is the empty fh:complete or fh:archive element.
+type fhMarker struct{}
+
+// Pages splits the Podcast into the current feed and archive pages
+// following RFC 5005, for shows whose back catalog is too large for a
+// single feed.
+//
+// The visible Items are split by publication date: full archive pages of
+// PageSize Items are filled from the oldest Item up, and the current feed
+// holds the newest Items left over, between PageSize and twice as many.
+// Archive pages therefore never change as new Items are added, until the
+// current feed fills up another one.
+//
+// The current feed is published at the url of the AtomLink, which is
+// required, and is returned first, followed by the archive pages from the
+// oldest.  Each page links to the others with the atom:link relations
+// first, last, next and prev, from the newest page to the oldest, and
+// current, prev-archive and next-archive.  Archive pages are marked with
+// fh:archive, and a current feed holding every Item with fh:complete.
+func (p *Podcast) Pages(o PageOptions) ([]*Page, error) {
+	if o.PageSize <= 0 {
+		return nil, errors.New("podcast.Pages: PageSize must be positive")
+	}
+	if p.AtomLink == nil || len(p.AtomLink.HREF) == 0 {
+		return nil, errors.New("podcast.Pages: AtomLink is required for the url of the current feed")
+	}
+
+	eo := o.EncodeOptions
+	eo.Order, eo.MaxItems, eo.MaxBytes = ItemOrderInsertion, 0, 0
+	e := p.encodable(eo)
+	items := e.Items
+	sort.SliceStable(items, func(a, b int) bool {
+		at, _ := items[a].publishTime()
+		bt, _ := items[b].publishTime()
+		return at.Before(bt)
+	})
+
+	archives := 0
+	if len(items) > o.PageSize {
+		archives = (len(items) - o.PageSize) / o.PageSize
+	}
+	if archives > 0 && !strings.Contains(o.URLTemplate, PagePlaceholder) {
+		return nil, errors.New("podcast.Pages: URLTemplate must contain " + PagePlaceholder)
+	}
+
+	current := p.AtomLink.HREF
+	url := func(n int) string {
+		if n == 0 {
+			return current
+		}
+		return strings.Replace(o.URLTemplate, PagePlaceholder, strconv.Itoa(n), -1)
+	}
+
+	encode := p.encode
+	if encode == nil {
+		encode = encoder
+	}
+	if o.Minify {
+		encode = minifiedEncoder
+	}
+
+	order := o.order(e.IType)
+	pages := make([]*Page, archives+1)
+	for n := range pages {
+		// the current feed holds the Items after the archive pages
+		start, end := (n-1)*o.PageSize, n*o.PageSize
+		if n == 0 {
+			start, end = archives*o.PageSize, len(items)
+		}
+		c := *e
+		c.Items = append([]*Item(nil), items[start:end]...)
+		sortItems(c.Items, order)
+
+		ch := &pageChannel{Podcast: &c}
+		link := func(rel string, n int) {
+			ch.Links = append(ch.Links, &AtomLink{
+				HREF: url(n),
+				Rel:  rel,
+				Type: "application/rss+xml",
+			})
+		}
+		link("self", n)
+		switch {
+		case archives == 0:
+			ch.Complete = &fhMarker{}
+		case n == 0:
+			link("first", 0)
+			link("next", archives)
+			link("last", 1)
+			link("prev-archive", archives)
+		default:
+			ch.Archive = &fhMarker{}
+			link("current", 0)
+			link("first", 0)
+			if n < archives {
+				link("prev", n+1)
+			} else {
+				link("prev", 0)
+			}
+			if n > 1 {
+				link("next", n-1)
+			}
+			link("last", 1)
+			if n > 1 {
+				link("prev-archive", n-1)
+			}
+			if n < archives {
+				link("next-archive", n+1)
+			}
+		}
+
+		pages[n] = &Page{
+			Number:  n,
+			URL:     url(n),
+			Items:   c.Items,
+			channel: ch,
+			encode:  encode,
+		}
+	}
+	return pages, nil
+}
+
+// Encode writes the Page to the io.Writer stream in RSS 2.0 specification.
+func (pg *Page) Encode(w io.Writer) error {
+	if _, err := w.Write([]byte(HEADER)); err != nil {
+		return errors.Wrap(err, "podcast.Page.Encode: w.Write return error")
+	}
+	wrapper := NewWrapper(pg.channel.Podcast)
+	var namespaces []xml.Attr
+	for _, a := range wrapper.Namespaces {
+		if a.Name.Local != "xmlns:fh" {
+			namespaces = append(namespaces, a)
+		}
+	}
+	wrapper.Namespaces = namespaces
+	return pg.encode(w, pageWrapper{
+		PodcastWrapper: wrapper,
+		FHNS:           FHNS,
+		Channel:        pg.channel,
+	})
+}
@@ -0,0 +1,147 @@
+package podcast_test
+
+import (
+	"bytes"
+	"strconv"
+	"testing"
+	"time"
+
+	podcast "github.com/podpalinc/rss-feed-generator"
+	"github.com/stretchr/testify/assert"
+)
+
+func newPagedTestPodcast(t *testing.T, items int) podcast.Podcast {
+	p := podcast.New("title", "http://example.com/", podcast.Description{Text: "Description"}, nil, nil)
+	p.AddAtomLink("http://example.com/feed.xml")
+	start := time.Date(2020, time.January, 1, 0, 0, 0, 0, time.UTC)
+	for n := 1; n <= items; n++ {
+		i := podcast.Item{Title: "episode " + strconv.Itoa(n), Description: &podcast.Description{Text: "d"}}
+		i.AddEnclosure("http://example.com/"+strconv.Itoa(n)+".mp3", podcast.MP3, "", 1)
+		i.AddPubDateTime(start.Add(time.Duration(n) * 24 * time.Hour))
+		if _, err := p.AddItem(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return p
+}
+
+func pageTitles(pg *podcast.Page) []string {
+	var titles []string
+	for _, i := range pg.Items {
+		titles = append(titles, i.Title)
+	}
+	return titles
+}
+
+func TestPagesSplit(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newPagedTestPodcast(t, 7)
+
+	// act
+	pages, err := p.Pages(podcast.PageOptions{
+		PageSize:      2,
+		URLTemplate:   "http://example.com/feed.xml?page={page}",
+		EncodeOptions: podcast.EncodeOptions{Order: podcast.ItemOrderNewestFirst},
+	})
+
+	// assert
+	if !assert.NoError(t, err) || !assert.Len(t, pages, 3) {
+		return
+	}
+	assert.Equal(t, 0, pages[0].Number)
+	assert.Equal(t, "http://example.com/feed.xml", pages[0].URL)
+	assert.Equal(t, []string{"episode 7", "episode 6", "episode 5"}, pageTitles(pages[0]))
+	assert.Equal(t, "http://example.com/feed.xml?page=1", pages[1].URL)
+	assert.Equal(t, []string{"episode 2", "episode 1"}, pageTitles(pages[1]))
+	assert.Equal(t, "http://example.com/feed.xml?page=2", pages[2].URL)
+	assert.Equal(t, []string{"episode 4", "episode 3"}, pageTitles(pages[2]))
+	assert.Len(t, p.Items, 7)
+}
+
+func TestPagesLinks(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newPagedTestPodcast(t, 7)
+	pages, err := p.Pages(podcast.PageOptions{
+		PageSize:    2,
+		URLTemplate: "http://example.com/archive/{page}.xml",
+	})
+	if !assert.NoError(t, err) {
+		return
+	}
+	var current, oldest, newest bytes.Buffer
+
+	// act
+	errCurrent := pages[0].Encode(&current)
+	errOldest := pages[1].Encode(&oldest)
+	errNewest := pages[2].Encode(&newest)
+
+	// assert
+	assert.NoError(t, errCurrent)
+	assert.NoError(t, errOldest)
+	assert.NoError(t, errNewest)
+	assert.Contains(t, current.String(), `xmlns:fh="http://purl.org/syndication/history/1.0"`)
+	assert.Contains(t, current.String(), `<atom:link href="http://example.com/feed.xml" rel="self" type="application/rss+xml"></atom:link>
+    <atom:link href="http://example.com/feed.xml" rel="first" type="application/rss+xml"></atom:link>
+    <atom:link href="http://example.com/archive/2.xml" rel="next" type="application/rss+xml"></atom:link>
+    <atom:link href="http://example.com/archive/1.xml" rel="last" type="application/rss+xml"></atom:link>
+    <atom:link href="http://example.com/archive/2.xml" rel="prev-archive" type="application/rss+xml"></atom:link>
+    <title>title</title>`)
+	assert.NotContains(t, current.String(), "<fh:")
+	assert.Contains(t, oldest.String(), `<atom:link href="http://example.com/archive/1.xml" rel="self" type="application/rss+xml"></atom:link>
+    <atom:link href="http://example.com/feed.xml" rel="current" type="application/rss+xml"></atom:link>
+    <atom:link href="http://example.com/feed.xml" rel="first" type="application/rss+xml"></atom:link>
+    <atom:link href="http://example.com/archive/2.xml" rel="prev" type="application/rss+xml"></atom:link>
+    <atom:link href="http://example.com/archive/1.xml" rel="last" type="application/rss+xml"></atom:link>
+    <atom:link href="http://example.com/archive/2.xml" rel="next-archive" type="application/rss+xml"></atom:link>
+    <fh:archive></fh:archive>`)
+	assert.Contains(t, newest.String(), `<atom:link href="http://example.com/archive/2.xml" rel="self" type="application/rss+xml"></atom:link>
+    <atom:link href="http://example.com/feed.xml" rel="current" type="application/rss+xml"></atom:link>
+    <atom:link href="http://example.com/feed.xml" rel="first" type="application/rss+xml"></atom:link>
+    <atom:link href="http://example.com/feed.xml" rel="prev" type="application/rss+xml"></atom:link>
+    <atom:link href="http://example.com/archive/1.xml" rel="next" type="application/rss+xml"></atom:link>
+    <atom:link href="http://example.com/archive/1.xml" rel="last" type="application/rss+xml"></atom:link>
+    <atom:link href="http://example.com/archive/1.xml" rel="prev-archive" type="application/rss+xml"></atom:link>
+    <fh:archive></fh:archive>`)
+}
+
+func TestPagesComplete(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newPagedTestPodcast(t, 3)
+	var b bytes.Buffer
+
+	// act
+	pages, err := p.Pages(podcast.PageOptions{PageSize: 3})
+
+	// assert
+	if !assert.NoError(t, err) || !assert.Len(t, pages, 1) {
+		return
+	}
+	assert.NoError(t, pages[0].Encode(&b))
+	assert.Contains(t, b.String(), `<atom:link href="http://example.com/feed.xml" rel="self" type="application/rss+xml"></atom:link>
+    <fh:complete></fh:complete>`)
+	assert.Len(t, pages[0].Items, 3)
+}
+
+func TestPagesErrors(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newPagedTestPodcast(t, 5)
+	noLink := podcast.New("title", "http://example.com/", podcast.Description{Text: "Description"}, nil, nil)
+
+	// act
+	_, errSize := p.Pages(podcast.PageOptions{})
+	_, errTemplate := p.Pages(podcast.PageOptions{PageSize: 2, URLTemplate: "http://example.com/archive.xml"})
+	_, errLink := noLink.Pages(podcast.PageOptions{PageSize: 2})
+
+	// assert
+	assert.EqualError(t, errSize, "podcast.Pages: PageSize must be positive")
+	assert.EqualError(t, errTemplate, "podcast.Pages: URLTemplate must contain {page}")
+	assert.EqualError(t, errLink, "podcast.Pages: AtomLink is required for the url of the current feed")
+}
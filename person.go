@@ -0,0 +1,148 @@
+package podcast
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Common podcast:person groups and roles from the Podcast Taxonomy Project.
+// See PersonRoles for the complete list.
+const (
+	PersonGroupCast              = "cast"
+	PersonGroupCreativeDirection = "creative direction"
+	PersonGroupWriting           = "writing"
+
+	PersonRoleHost     = "host"
+	PersonRoleCoHost   = "co-host"
+	PersonRoleGuest    = "guest"
+	PersonRoleProducer = "producer"
+	PersonRoleEditor   = "editor"
+)
+
+// PersonRoles is the official podcast:person taxonomy, mapping each group to
+// the roles that belong to it.  Groups and roles are compared in lower case.
+//
+// Taxonomy: https://github.com/Podcastindex-org/podcast-namespace/blob/main/taxonomy.json
+var PersonRoles = map[string][]string{
+	"creative direction": {
+		"director", "assistant director", "executive producer", "senior producer",
+		"producer", "associate producer", "development producer", "creative director",
+	},
+	"cast": {
+		"host", "co-host", "guest host", "guest", "voice actor", "narrator",
+		"announcer", "reporter",
+	},
+	"writing": {
+		"author", "editorial director", "co-writer", "writer", "songwriter",
+		"guest writer", "story editor", "managing editor", "script editor",
+		"script coordinator", "researcher", "editor", "fact checker", "translator",
+		"transcriber", "logger",
+	},
+	"audio post-production": {
+		"studio coordinator", "technical director", "technical manager",
+		"audio engineer", "remote recording engineer", "post production engineer",
+	},
+	"audio production": {
+		"audio editor", "sound designer", "foley artist", "composer", "theme music",
+		"music production", "music contributor",
+	},
+	"administration": {
+		"production coordinator", "booking coordinator", "production assistant",
+		"content manager", "marketing manager", "sales representative",
+		"sales manager",
+	},
+	"visuals": {
+		"graphic designer", "cover art designer",
+	},
+	"community": {
+		"social media manager",
+	},
+	"misc.": {
+		"consultant", "intern",
+	},
+	"video production": {
+		"camera operator", "lighting designer", "camera grip", "assistant camera",
+	},
+	"video post-production": {
+		"editor", "assistant editor",
+	},
+}
+
+// Person represents a podcast:person tag crediting someone who contributed
+// to the show or episode.
+//
+// When Role and Group are empty, readers assume the "host" role of the
+// "cast" group.
+//
+// Specification: https://github.com/Podcastindex-org/podcast-namespace/blob/main/docs/1.0.md#person
+type Person struct {
+	XMLName xml.Name `xml:"podcast:person"`
+	Role    string   `xml:"role,attr,omitempty"`
+	Group   string   `xml:"group,attr,omitempty"`
+	Img     string   `xml:"img,attr,omitempty"`
+	Href    string   `xml:"href,attr,omitempty"`
+	Name    string   `xml:",chardata"`
+}
+
+// isHost reports whether the person is credited as a host of the show,
+// which is the role the legacy itunes:author tag is derived from.
+func (p *Person) isHost() bool {
+	group := strings.ToLower(p.Group)
+	if group != "" && group != PersonGroupCast {
+		return false
+	}
+	role := strings.ToLower(p.Role)
+	return role == "" || role == PersonRoleHost || role == PersonRoleCoHost
+}
+
+// normalizePerson lower cases the Role and Group of the person and confirms
+// they are part of the taxonomy.
+func normalizePerson(p *Person) error {
+	if len(strings.TrimSpace(p.Name)) == 0 {
+		return errors.New("Person.Name is required")
+	}
+	p.Role = strings.ToLower(strings.TrimSpace(p.Role))
+	p.Group = strings.ToLower(strings.TrimSpace(p.Group))
+
+	if len(p.Group) > 0 {
+		roles, ok := PersonRoles[p.Group]
+		if !ok {
+			return errors.New(p.Name + ": unknown person group " + p.Group)
+		}
+		if len(p.Role) > 0 && !containsString(roles, p.Role) {
+			return errors.New(p.Name + ": role " + p.Role + " is not part of group " + p.Group)
+		}
+		return nil
+	}
+	if len(p.Role) > 0 {
+		for _, roles := range PersonRoles {
+			if containsString(roles, p.Role) {
+				return nil
+			}
+		}
+		return errors.New(p.Name + ": unknown person role " + p.Role)
+	}
+	return nil
+}
+
+// hostNames returns the names of the people credited as hosts.
+func hostNames(people []*Person) []string {
+	var names []string
+	for _, p := range people {
+		if p.isHost() {
+			names = append(names, p.Name)
+		}
+	}
+	return names
+}
+
+func containsString(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
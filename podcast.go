@@ -5,6 +5,8 @@ import (
 	"encoding/xml"
 	"fmt"
 	"io"
+	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
@@ -23,6 +25,8 @@ const (
 	GOOGLEPLAYNS = "http://www.google.com/schemas/play-podcasts/1.0"
 	SPOTIFYNS    = "http://www.spotify.com/ns/rss"
 	CONTENT      = "http://purl.org/rss/1.0/modules/content/"
+	PODCASTNS    = "https://podcastindex.org/namespace/1.0"
+	FHNS         = "http://purl.org/syndication/history/1.0"
 )
 
 // Podcast represents a podcast.
@@ -48,6 +52,13 @@ type Podcast struct {
 	Image          *Image
 	TextInput      *TextInput
 
+	// PubDateTime and LastBuildDateTime override PubDate and LastBuildDate
+	// in RFC 1123Z format when the feed is encoded.
+	PubDateTime       time.Time `xml:"-"`
+	LastBuildDateTime time.Time `xml:"-"`
+	// Explicit overrides IExplicit when the feed is encoded, unless nil.
+	Explicit *bool `xml:"-"`
+
 	// https://help.apple.com/itc/podcasts_connect/#/itcb54353390
 	ITitle      string `xml:"itunes:title,omitempty"`
 	IAuthor     string `xml:"itunes:author,omitempty"`
@@ -56,20 +67,47 @@ type Podcast struct {
 	ISummary    *ISummary
 	IBlock      string `xml:"itunes:block,omitempty"`
 	IImage      *IImage
-	IDuration   string  `xml:"itunes:duration,omitempty"`
-	IExplicit   string  `xml:"itunes:explicit,omitempty"`
-	IComplete   string  `xml:"itunes:complete,omitempty"`
-	INewFeedURL string  `xml:"itunes:new-feed-url,omitempty"`
-	IOwner      *Author // Author is formatted for itunes as-is
-	ICategories []*ICategory
+	IDuration   string       `xml:"itunes:duration,omitempty"`
+	IExplicit   string       `xml:"itunes:explicit,omitempty"`
+	IComplete   string       `xml:"itunes:complete,omitempty"`
+	INewFeedURL string       `xml:"itunes:new-feed-url,omitempty"`
+	IOwner      *Author      // Author is formatted for itunes as-is
+	ICategories []*ICategory `xml:"itunes:category"`
+
+	// https://github.com/Podcastindex-org/podcast-namespace/blob/main/docs/1.0.md
+	Locked  *Locked
+	GUID    string     `xml:"podcast:guid,omitempty"`
+	Persons []*Person  `xml:"podcast:person"`
+	Funding []*Funding `xml:"podcast:funding"`
+	Values  []*Value   `xml:"podcast:value"`
+
+	LiveItems []*LiveItem `xml:"podcast:liveItem"`
 
 	// https://support.google.com/podcast-publishers/answer/9889544?hl=en
-	// GooglePlayAuthor      string `xml:"googleplay:author,omitempty"`
-	// GooglePlayDescription string `xml:"googleplay:description,omitempty"`
-	// GooglePlayOwner       string `xml:"googleplay:owner,omitempty"`
-	// GooglePlayImage       *GooglePlayImage
-
-	Items []*Item
+	GooglePlayAuthor      string `xml:"googleplay:author,omitempty"`
+	GooglePlayDescription string `xml:"googleplay:description,omitempty"`
+	GooglePlayEmail       string `xml:"googleplay:email,omitempty"`
+	GooglePlayImage       *GooglePlayImage
+	GooglePlayCategories  []*GooglePlayCategory `xml:"googleplay:category"`
+	GooglePlayExplicit    string                `xml:"googleplay:explicit,omitempty"`
+	GooglePlayBlock       string                `xml:"googleplay:block,omitempty"`
+
+	// https://podcasters.spotify.com/terms/Spotify_Podcast_Delivery_Specification_v1.6.pdf
+	SpotifyLimit           *SpotifyLimit
+	SpotifyCountryOfOrigin string `xml:"spotify:countryOfOrigin,omitempty"`
+
+	// Namespaces declared on the rss element besides the built-in ones,
+	// by prefix, for the Extensions to use.  See AddNamespace.
+	Namespaces map[string]string `xml:"-"`
+	// Extensions are the elements of other namespaces, written after the
+	// built-in fields.  See AddExtension.
+	Extensions []*Extension `xml:",any"`
+
+	Items []*Item `xml:"item"`
+
+	// DuplicateGUIDs is the policy of AddItem for an Item with the GUID of
+	// another Item.  The default, DuplicateGUIDAllow, appends it anyway.
+	DuplicateGUIDs DuplicateGUIDPolicy `xml:"-"`
 
 	encode func(w io.Writer, o interface{}) error
 }
@@ -80,13 +118,20 @@ type Podcast struct {
 // to the expected proper formats.
 func New(title, link string, description Description,
 	pubDate, lastBuildDate *time.Time) Podcast {
-	return Podcast{
+	p := Podcast{
 		Title:       GenerateFeedString(title),
 		Link:        link,
 		Description: &description,
 		// setup dependency (could inject later)
 		encode: encoder,
 	}
+	if pubDate != nil {
+		p.AddPubDateTime(*pubDate)
+	}
+	if lastBuildDate != nil {
+		p.AddLastBuildDateTime(*lastBuildDate)
+	}
+	return p
 }
 
 func (p *Podcast) AddTitle(title string) {
@@ -120,6 +165,53 @@ func (p *Podcast) AddAuthor(authors []string) {
 
 	author := GenerateFeedString(combinedAuthors)
 	p.IAuthor = author
+	p.GooglePlayAuthor = author
+}
+
+// AddPerson credits a person on the Podcast with a podcast:person tag.
+//
+// The Role and Group must come from the official taxonomy listed in
+// PersonRoles and are stored in lower case.  Both may be left empty, in
+// which case readers assume a host of the cast.
+//
+// Hosts and co-hosts are also joined into the legacy itunes:author tag, as
+// with AddAuthor, for the apps that do not support podcast:person.
+func (p *Podcast) AddPerson(person Person) error {
+	if err := normalizePerson(&person); err != nil {
+		return err
+	}
+	p.Persons = append(p.Persons, &person)
+
+	if person.isHost() {
+		p.AddAuthor(hostNames(p.Persons))
+	}
+	return nil
+}
+
+// AddFunding adds a podcast:funding link to a page where listeners can
+// support the show.  The text is shown by apps as the call to action and is
+// truncated to 128 characters.
+//
+// Calling this method multiple times will APPEND the link to the existing
+// list, if any.
+func (p *Podcast) AddFunding(url, text string) {
+	if f := newFunding(url, text); f != nil {
+		p.Funding = append(p.Funding, f)
+	}
+}
+
+// AddValue adds a podcast:value block to the Podcast, describing how
+// listeners can send payments to the show.  A show may offer one block per
+// payment type.
+//
+// It returns an error when the block has no type or method, or when the
+// recipient splits are not well-formed.
+func (p *Podcast) AddValue(v Value) error {
+	if err := validateValue(&v); err != nil {
+		return errors.Wrap(err, "podcast.AddValue")
+	}
+	p.Values = append(p.Values, &v)
+	return nil
 }
 
 // AddAtomLink adds a FQDN reference to an atom feed.
@@ -134,99 +226,153 @@ func (p *Podcast) AddAtomLink(href string) {
 	}
 }
 
-// AddCategory adds the category to the Podcast.
-//
-// ICategory can be listed multiple times.
-//
-// Calling this method multiple times will APPEND the category to the existing
-// list, if any, including ICategory.
-//
-// Note that Apple iTunes has a specific list of categories that only can be
-// used and will invalidate the feed if deviated from the list.  That list is
-// as follows.
-//
-//   * Arts
-//     * Design
-//     * Fashion & Beauty
-//     * Food
-//     * Literature
-//     * Performing Arts
-//     * Visual Arts
-//   * Business
-//     * Business News
-//     * Careers
-//     * Investing
-//     * Management & Marketing
-//     * Shopping
-//   * Comedy
-//   * Education
-//     * Education Technology
-//     * Higher Education
-//     * K-12
-//     * Language Courses
-//     * Training
-//   * Games & Hobbies
-//     * Automotive
-//     * Aviation
-//     * Hobbies
-//     * Other Games
-//     * Video Games
-//   * Government & Organizations
-//     * Local
-//     * National
-//     * Non-Profit
-//     * Regional
-//   * Health
-//     * Alternative Health
-//     * Fitness & Nutrition
-//     * Self-Help
-//     * Sexuality
-//   * Kids & Family
-//   * Music
-//   * News & Politics
-//   * Religion & Spirituality
-//     * Buddhism
-//     * Christianity
-//     * Hinduism
-//     * Islam
-//     * Judaism
-//     * Other
-//     * Spirituality
-//   * Science & Medicine
-//     * Medicine
-//     * Natural Sciences
-//     * Social Sciences
-//   * Society & Culture
-//     * History
-//     * Personal Journals
-//     * Philosophy
-//     * Places & Travel
-//   * Sports & Recreation
-//     * Amateur
-//     * College & High School
-//     * Outdoor
-//     * Professional
-//   * Technology
-//     * Gadgets
-//     * Podcasting
-//     * Software How-To
-//     * Tech News
-//   * TV & Film
-func (p *Podcast) AddCategory(category string, subCategories []string) {
-	if len(category) == 0 {
+// AddGUID sets the podcast:guid, the globally unique and permanent
+// identifier of the show.  Use GenerateFeedGUID to compute it from the
+// original feed url.
+//
+// Once set, the GUID must not change, even when the feed moves to a new
+// url with AddNewFeedURL.
+func (p *Podcast) AddGUID(guid string) {
+	if len(guid) == 0 {
+		return
+	}
+	p.GUID = strings.ToLower(guid)
+}
+
+// AddLocked sets the podcast:locked tag.  A locked feed tells other podcast
+// hosts not to import it; the owner email is used by hosts to verify an
+// import request and is required to lock the feed.
+func (p *Podcast) AddLocked(locked bool, owner string) {
+	if !locked {
+		p.Locked = &Locked{Owner: owner, Value: "no"}
 		return
 	}
+	if len(owner) == 0 {
+		return
+	}
+	p.Locked = &Locked{Owner: owner, Value: "yes"}
+}
+
+// AddCategory adds the Apple category, and its subcategories, to the
+// Podcast.  See AddAppleCategory for the typed Category constants.
+//
+// Categories Apple retired in 2019 are replaced with their current ones, see
+// LookupCategory.  An error is returned, and nothing is added, if a name is
+// not in the Apple taxonomy.  Empty subcategories are ignored.
+func (p *Podcast) AddCategory(category string, subCategories []string) error {
+	if len(category) == 0 {
+		return errors.New("category is required")
+	}
 
-	icat := ICategory{Text: category}
-	for _, c := range subCategories {
-		if len(c) == 0 {
+	type selection struct{ category, subcategory Category }
+	var selected []selection
+	for _, sub := range subCategories {
+		if len(sub) == 0 {
 			continue
 		}
-		icat2 := ICategory{Text: c}
-		icat.ICategories = append(icat.ICategories, &icat2)
+		c, s, err := LookupCategory(category, sub)
+		if err != nil {
+			return err
+		}
+		selected = append(selected, selection{c, s})
+	}
+	if len(selected) == 0 {
+		c, _, err := LookupCategory(category, "")
+		if err != nil {
+			return err
+		}
+		selected = append(selected, selection{c, ""})
+	}
+
+	for _, s := range selected {
+		p.addICategory(s.category, s.subcategory)
+	}
+	p.AddGooglePlayCategory(category)
+	return nil
+}
+
+// AddAppleCategory adds the Apple category, and its subcategories, to the
+// Podcast using the Category constants.
+//
+// Calling this method multiple times merges the categories, which are kept in
+// the order of the Apple taxonomy so the encoded feed does not depend on the
+// order they were added in.
+//
+// An error is returned, and nothing is added, if the subcategories do not
+// belong to the category.
+func (p *Podcast) AddAppleCategory(category Category, subcategories ...Category) error {
+	if category.index() < 0 {
+		return errors.New(string(category) + ": unknown Apple Podcasts category")
+	}
+	for _, s := range subcategories {
+		if category.subcategoryIndex(s) < 0 {
+			return errors.New(string(s) + ": not a subcategory of Apple Podcasts category " + string(category))
+		}
+	}
+
+	if len(subcategories) == 0 {
+		p.addICategory(category, "")
+	}
+	for _, s := range subcategories {
+		p.addICategory(category, s)
+	}
+	p.AddGooglePlayCategory(string(category))
+	return nil
+}
+
+// addICategory merges the valid category and subcategory into ICategories,
+// keeping both in the order of the Apple taxonomy.
+func (p *Podcast) addICategory(category, subcategory Category) {
+	var icat *ICategory
+	for _, c := range p.ICategories {
+		if c.Text == string(category) {
+			icat = c
+			break
+		}
+	}
+	if icat == nil {
+		icat = &ICategory{Text: string(category)}
+		p.ICategories = append(p.ICategories, icat)
+		sort.SliceStable(p.ICategories, func(a, b int) bool {
+			return Category(p.ICategories[a].Text).index() < Category(p.ICategories[b].Text).index()
+		})
+	}
+	if len(subcategory) == 0 {
+		return
+	}
+	for _, c := range icat.ICategories {
+		if c.Text == string(subcategory) {
+			return
+		}
 	}
+	icat.ICategories = append(icat.ICategories, &ICategory{Text: string(subcategory)})
+	sort.SliceStable(icat.ICategories, func(a, b int) bool {
+		return category.subcategoryIndex(Category(icat.ICategories[a].Text)) <
+			category.subcategoryIndex(Category(icat.ICategories[b].Text))
+	})
+}
 
-	p.ICategories = append(p.ICategories, &icat)
+// AddGooglePlayCategory adds the Google Play category matching the Apple
+// category, as listed in GooglePlayCategories.  AddCategory calls this for
+// you.
+//
+// Google Play categories are added once, so Apple categories sharing the
+// same Google Play category do not repeat it, and are kept in the order of
+// Google's category list.
+func (p *Podcast) AddGooglePlayCategory(appleCategory string) {
+	category, ok := GooglePlayCategories[appleCategory]
+	if !ok {
+		return
+	}
+	for _, c := range p.GooglePlayCategories {
+		if c.Text == category {
+			return
+		}
+	}
+	p.GooglePlayCategories = append(p.GooglePlayCategories, &GooglePlayCategory{Text: category})
+	sort.SliceStable(p.GooglePlayCategories, func(a, b int) bool {
+		return googlePlayCategoryIndex(p.GooglePlayCategories[a].Text) < googlePlayCategoryIndex(p.GooglePlayCategories[b].Text)
+	})
 }
 
 func (p *Podcast) AddCopyright(copyright string) {
@@ -236,61 +382,32 @@ func (p *Podcast) AddCopyright(copyright string) {
 	p.Copyright = GenerateFeedString(copyright)
 }
 
-type podcastCategory struct {
-	Name            string
-	ParentCategory  string
-	ChildCategories []string
-}
-
-// Utility function to parse categories and divide them into
+// ParseCategories divides the Apple category and subcategory names into a
+// map of categories to their subcategories, ready for AddCategory.
+//
+// Retired category names are replaced with their current ones and names not
+// in the Apple taxonomy are skipped.  As the map is unordered, prefer
+// AddAppleCategory or LookupCategory.
 func ParseCategories(categories []string) map[string][]string {
-	PODCAST_CATEGORIES := []podcastCategory{}
-	PODCAST_CATEGORIES = append(PODCAST_CATEGORIES, podcastCategory{Name: "Arts", ChildCategories: []string{"Books", "Design", "Fashion & Beauty", "Food", "Performing Arts", "Visual Arts"}})
-	PODCAST_CATEGORIES = append(PODCAST_CATEGORIES, podcastCategory{Name: "Business", ChildCategories: []string{"Careers", "Entrepreneurship", "Investing", "Management", "Marketing", "Non-Profit"}})
-	PODCAST_CATEGORIES = append(PODCAST_CATEGORIES, podcastCategory{Name: "Comedy", ChildCategories: []string{"Comedy Interviews", "Improv", "Stand-Up"}})
-	PODCAST_CATEGORIES = append(PODCAST_CATEGORIES, podcastCategory{Name: "Education", ChildCategories: []string{"Courses", "How To", "Language Learning", "Self-Improvement"}})
-	PODCAST_CATEGORIES = append(PODCAST_CATEGORIES, podcastCategory{Name: "Fiction", ChildCategories: []string{"Comedy Fiction", "Drama", "Science Fiction"}})
-	PODCAST_CATEGORIES = append(PODCAST_CATEGORIES, podcastCategory{Name: "Government", ChildCategories: []string{}})
-	PODCAST_CATEGORIES = append(PODCAST_CATEGORIES, podcastCategory{Name: "History", ChildCategories: []string{}})
-	PODCAST_CATEGORIES = append(PODCAST_CATEGORIES, podcastCategory{Name: "Health & Fitness", ChildCategories: []string{"Alternative Health", "Fitness", "Medicine", "Mental Health", "Nutrition", "Sexuality"}})
-	PODCAST_CATEGORIES = append(PODCAST_CATEGORIES, podcastCategory{Name: "Kids & Family", ChildCategories: []string{"Education for Kids", "Parenting", "Pets & Animals", "Stories for Kids"}})
-	PODCAST_CATEGORIES = append(PODCAST_CATEGORIES, podcastCategory{Name: "Leisure", ChildCategories: []string{"Animation & Manga", "Automotive", "Aviation", "Crafts", "Games", "Hobbies", "Home & Garden", "Video Games"}})
-	PODCAST_CATEGORIES = append(PODCAST_CATEGORIES, podcastCategory{Name: "Music", ChildCategories: []string{"Music Commentary", "Music History", "Music Interviews"}})
-	PODCAST_CATEGORIES = append(PODCAST_CATEGORIES, podcastCategory{Name: "News", ChildCategories: []string{"Business News", "Daily News", "Entertainment News", "News Commentary", "Politics", "Sports News", "Tech News"}})
-	PODCAST_CATEGORIES = append(PODCAST_CATEGORIES, podcastCategory{Name: "Religion & Spirituality", ChildCategories: []string{"Buddhism", "Christianity", "Hinduism", "Islam", "Judaism", "Religion", "Spirituality"}})
-	PODCAST_CATEGORIES = append(PODCAST_CATEGORIES, podcastCategory{Name: "Science", ChildCategories: []string{"Astronomy", "Chemistry", "Earth Sciences", "Life Sciences", "Mathematics", "Natural Sciences", "Nature", "Physics", "Social Sciences"}})
-	PODCAST_CATEGORIES = append(PODCAST_CATEGORIES, podcastCategory{Name: "Society & Culture", ChildCategories: []string{"Documentary", "Personal Journals", "Philosophy", "Places & Travel", "Relationships"}})
-	PODCAST_CATEGORIES = append(PODCAST_CATEGORIES, podcastCategory{Name: "Sports", ChildCategories: []string{"Baseball", "Basketball", "Cricket", "Fantasy Sports", "Football", "Golf", "Hockey", "Rugby", "Running", "Soccer", "Swimming", "Tennis", "Volleyball", "Wilderness", "Wrestling"}})
-	PODCAST_CATEGORIES = append(PODCAST_CATEGORIES, podcastCategory{Name: "Technology", ChildCategories: []string{}})
-	PODCAST_CATEGORIES = append(PODCAST_CATEGORIES, podcastCategory{Name: "True Crime", ChildCategories: []string{}})
-	PODCAST_CATEGORIES = append(PODCAST_CATEGORIES, podcastCategory{Name: "TV & Film", ChildCategories: []string{"After Shows", "Film History", "Film Interviews", "Film Reviews", "TV Reviews"}})
-
-	for _, pc := range PODCAST_CATEGORIES {
-		for _, child := range pc.ChildCategories {
-			PODCAST_CATEGORIES = append(PODCAST_CATEGORIES, podcastCategory{Name: child, ParentCategory: pc.Name})
-		}
-	}
-
-	var findPodcastCategory = func(category string) *podcastCategory {
-		for _, pc := range PODCAST_CATEGORIES {
-			if &pc != nil && pc.Name == category {
-				return &pc
+	parsedCategories := make(map[string][]string)
+
+	for _, name := range categories {
+		category, subcategory, err := LookupCategory(name, "")
+		if err != nil {
+			parent, ok := subcategoryParent(Category(name))
+			if !ok {
+				continue
 			}
+			category, subcategory = parent, Category(name)
 		}
-		return nil
-	}
-
-	parsedCategories := make(map[string][]string)
 
-	for _, category := range categories {
-		pc := findPodcastCategory(category)
-		if &pc != nil && pc != nil && pc.ParentCategory != "" {
-			parentCat := GenerateFeedString(pc.ParentCategory)
-			parsedCategories[parentCat] = append(parsedCategories[parentCat], strings.Replace(pc.Name, "&", "&amp;", -1))
-		} else {
-			cat := GenerateFeedString(pc.Name)
+		cat := string(category)
+		if _, ok := parsedCategories[cat]; !ok {
 			parsedCategories[cat] = []string{}
 		}
+		if len(subcategory) > 0 {
+			parsedCategories[cat] = append(parsedCategories[cat], string(subcategory))
+		}
 	}
 
 	return parsedCategories
@@ -307,6 +424,7 @@ func (p *Podcast) AddDescription(description Description) {
 	p.ISummary = &ISummary{
 		Text: html2text.HTML2Text(description.Text),
 	}
+	p.GooglePlayDescription = p.Description.Text
 }
 
 func (p *Podcast) AddGenerator(generator string) {
@@ -317,6 +435,16 @@ func (p *Podcast) AddGenerator(generator string) {
 	p.Generator = generator
 }
 
+// AddLastBuildDateTime sets the time the feed content last changed,
+// formatted as RFC 1123Z when the feed is encoded.
+func (p *Podcast) AddLastBuildDateTime(datetime time.Time) {
+	if datetime.IsZero() {
+		return
+	}
+
+	p.LastBuildDateTime = datetime
+}
+
 func (p *Podcast) AddLastBuildDate(datetime string) {
 	if len(datetime) == 0 {
 		return
@@ -325,134 +453,31 @@ func (p *Podcast) AddLastBuildDate(datetime string) {
 	p.LastBuildDate = datetime
 }
 
-// Podcast Language Codes.
-// en: "English",
-// af: "Afrikaans",
-// sq: "Albanian",
-// an: "Aragonese",
-// ar: "Arabic",
-// hy: "Armenian",
-// as: "Assamese",
-// az: "Azerbaijani",
-// eu: "Basque",
-// be: "Belarusian",
-// bn: "Bengali",
-// bs: "Bosnian",
-// br: "Breton",
-// bg: "Bulgarian",
-// my: "Burmese",
-// ca: "Catalan",
-// ch: "Chamorro",
-// ce: "Chechen",
-// zh: "Chinese",
-// cv: "Chuvash",
-// co: "Corsican",
-// cr: "Cree",
-// hr: "Croatian",
-// cs: "Czech",
-// da: "Danish",
-// nl: "Dutch",
-// eo: "Esperanto",
-// et: "Estonian",
-// fo: "Faeroese",
-// fj: "Fijian",
-// fi: "Finnish",
-// fr: "French",
-// fy: "Frisian",
-// gd: "Gaelic",
-// gl: "Galacian",
-// ka: "Georgian",
-// de: "German",
-// el: "Greek",
-// gu: "Gujurati",
-// ht: "Haitian",
-// he: "Hebrew",
-// hi: "Hindi",
-// hu: "Hungarian",
-// is: "Icelandic",
-// id: "Indonesian",
-// iu: "Inuktitut",
-// ga: "Irish",
-// it: "Italian",
-// ja: "Japanese",
-// kn: "Kannada",
-// ks: "Kashmiri",
-// kk: "Kazakh",
-// km: "Khmer",
-// ky: "Kirghiz",
-// ko: "Korean",
-// la: "Latin",
-// lv: "Latvian",
-// lt: "Lithuanian",
-// lb: "Luxembourgish",
-// mk: "FYRO Macedonian",
-// ms: "Malay",
-// ml: "Malayalam",
-// mt: "Maltese",
-// mi: "Maori",
-// mr: "Marathi",
-// mo: "Moldavian",
-// nv: "Navajo",
-// ng: "Ndonga",
-// ne: "Nepali",
-// no: "Norwegian",
-// oc: "Occitan",
-// or: "Oriya",
-// om: "Oromo",
-// fa: "Persian",
-// pl: "Polish",
-// pt: "Portuguese",
-// pa: "Punjabi",
-// qu: "Quechua",
-// rm: "Rhaeto-Romanic",
-// ro: "Romanian",
-// ru: "Russian",
-// sz: "Sami (Lappish)",
-// sg: "Sango",
-// sa: "Sanskrit",
-// sc: "Sardinian",
-// sd: "Sindhi",
-// si: "Singhalese",
-// sr: "Serbian",
-// sk: "Slovak",
-// sl: "Slovenian",
-// so: "Somani",
-// sb: "Sorbian",
-// es: "Spanish",
-// sx: "Sutu",
-// sw: "Swahili",
-// sv: "Swedish",
-// ta: "Tamil",
-// tt: "Tatar",
-// te: "Teluga",
-// th: "Thai",
-// ts: "Tsonga",
-// tn: "Tswana",
-// tr: "Turkish",
-// tk: "Turkmen",
-// uk: "Ukrainian",
-// ur: "Urdu",
-// ve: "Venda",
-// vi: "Vietnamese",
-// vo: "Volapuk",
-// wa: "Walloon",
-// cy: "Welsh",
-// xh: "Xhosa",
-// ji: "Yiddish",
-// zu: "Zulu",
-func (p *Podcast) AddLanguage(language string) {
-	if len(language) < 2 {
-		return
+// AddLanguage sets the language of the Podcast to the canonical form of the
+// BCP 47 language tag, such as en-US for en-us, see ParseLanguage.
+//
+// An empty language is ignored.  An invalid one returns an error and leaves
+// the Language of the Podcast unchanged.
+func (p *Podcast) AddLanguage(language string) error {
+	if len(language) == 0 {
+		return nil
 	}
 
-	p.Language = GenerateFeedString(language)
+	tag, err := ParseLanguage(language)
+	if err != nil {
+		return err
+	}
+	p.Language = tag
+	return nil
 }
 
 func (p *Podcast) AddParentalAdvisory(parentalAdvisory string) {
 	if parentalAdvisory == ParentalAdvisoryExplicit {
 		p.IExplicit = "yes"
+		p.GooglePlayExplicit = googlePlayYesNo(true)
 	} else if parentalAdvisory == ParentalAdvisoryClean {
 		p.IExplicit = "no"
+		p.GooglePlayExplicit = googlePlayYesNo(false)
 	}
 
 	return
@@ -476,6 +501,7 @@ func (p *Podcast) AddImage(url string) {
 		Link:  p.Link,
 	}
 	p.IImage = &IImage{HREF: url}
+	p.GooglePlayImage = &GooglePlayImage{HREF: url}
 }
 
 // AddItem adds the podcast episode.  It returns a count of Items added or any
@@ -507,7 +533,7 @@ func (p *Podcast) AddImage(url string) {
 //   * GUID
 //   * PubDateFormatted
 //   * AuthorFormatted
-//   * Enclosure.TypeFormatted
+//   * Enclosure.TypeFormatted, unless Enclosure.Type is UnknownEnclosureType
 //   * Enclosure.LengthFormatted
 //
 // Recommendations:
@@ -519,23 +545,166 @@ func (p *Podcast) AddImage(url string) {
 //   * For specifications of itunes tags, see:
 //     https://help.apple.com/itc/podcasts_connect/#/itcb54353390
 //
+// When the GUID is already used by another Item, DuplicateGUIDs decides
+// whether the Item is appended, rejected or merged into the existing one.
 func (p *Podcast) AddItem(i Item) (int, error) {
+	prepared := i
+	if err := p.prepareItem(&prepared); err != nil {
+		return len(p.Items), err
+	}
+
+	if n := p.itemIndex(prepared.GUID.Value); n >= 0 {
+		switch p.DuplicateGUIDs {
+		case DuplicateGUIDReject:
+			return len(p.Items), errors.New(prepared.GUID.Value + ": duplicate Item GUID")
+		case DuplicateGUIDMerge:
+			// merge before the overrides, which would shadow the existing
+			// values with the defaults of the Podcast
+			i.merge(p.Items[n])
+			if err := p.prepareItem(&i); err != nil {
+				return len(p.Items), err
+			}
+			p.Items[n] = &i
+			return len(p.Items), nil
+		}
+	}
+
+	p.Items = append(p.Items, &prepared)
+	return len(p.Items), nil
+}
+
+// FindItem returns the Item with the GUID, or nil if there is none.  The
+// first Item is returned when DuplicateGUIDAllow let several share it.
+func (p *Podcast) FindItem(guid string) *Item {
+	if n := p.itemIndex(guid); n >= 0 {
+		return p.Items[n]
+	}
+	return nil
+}
+
+// UpdateItem replaces the Item with the same GUID, keeping its position in
+// the feed, e.g. when an episode is re-uploaded.
+//
+// The Item is validated and overridden as with AddItem, so leaving the GUID
+// empty derives it from the Enclosure URL.  An error is returned if the
+// Item is invalid or there is no Item with its GUID.
+func (p *Podcast) UpdateItem(i Item) error {
+	if err := p.prepareItem(&i); err != nil {
+		return err
+	}
+
+	n := p.itemIndex(i.GUID.Value)
+	if n < 0 {
+		return errors.New(i.GUID.Value + ": Item not found")
+	}
+	p.Items[n] = &i
+	return nil
+}
+
+// UpsertItem replaces the Item with the same GUID or, when there is none,
+// adds the Item as with AddItem.  It returns a count of Items or any errors
+// in validation.
+func (p *Podcast) UpsertItem(i Item) (int, error) {
+	if err := p.prepareItem(&i); err != nil {
+		return len(p.Items), err
+	}
+
+	if n := p.itemIndex(i.GUID.Value); n >= 0 {
+		p.Items[n] = &i
+		return len(p.Items), nil
+	}
+	p.Items = append(p.Items, &i)
+	return len(p.Items), nil
+}
+
+// RemoveItem removes the Items with the GUID from the feed.  An error is
+// returned if there is none.
+func (p *Podcast) RemoveItem(guid string) error {
+	var items []*Item
+	for _, i := range p.Items {
+		if i.GUID == nil || i.GUID.Value != guid {
+			items = append(items, i)
+		}
+	}
+	if len(items) == len(p.Items) {
+		return errors.New(guid + ": Item not found")
+	}
+	p.Items = items
+	return nil
+}
+
+// itemIndex returns the index of the first Item with the GUID, or -1.
+func (p *Podcast) itemIndex(guid string) int {
+	for n, i := range p.Items {
+		if i.GUID != nil && i.GUID.Value == guid {
+			return n
+		}
+	}
+	return -1
+}
+
+// merge fills the empty fields of the Item from the existing Item with its
+// GUID, for DuplicateGUIDMerge.  The State is not merged, its zero value
+// being ItemStatePublished.
+func (i *Item) merge(existing *Item) {
+	to, from := reflect.ValueOf(i).Elem(), reflect.ValueOf(existing).Elem()
+	for n := 0; n < to.NumField(); n++ {
+		switch to.Type().Field(n).Name {
+		case "XMLName", "State":
+			continue
+		}
+		f := to.Field(n)
+		if f.IsZero() || f.Kind() == reflect.Slice && f.Len() == 0 {
+			f.Set(from.Field(n))
+		}
+	}
+}
+
+// prepareItem performs the validation, overrides and iTunes inheritance of
+// AddItem on the Item.
+func (p *Podcast) prepareItem(i *Item) error {
+	defaults := 0
+	for _, a := range i.AlternateEnclosures {
+		if a == nil {
+			return errors.New(i.Title + ": AlternateEnclosure is nil")
+		}
+		if err := validateAlternateEnclosure(a); err != nil {
+			return errors.Wrap(err, i.Title)
+		}
+		if a.Default {
+			defaults++
+		}
+	}
+	if defaults > 1 {
+		return errors.New(i.Title + ": only one AlternateEnclosure can be the Default")
+	}
+
+	if i.Enclosure == nil {
+		i.Enclosure = i.fallbackEnclosure()
+	}
+
 	// initial guards for required fields
 	if len(i.Title) == 0 {
-		return len(p.Items), errors.New("Title and Description are required")
+		return errors.New("Title and Description are required")
 	}
 	if i.Enclosure != nil {
 		if len(i.Enclosure.URL) == 0 {
-			return len(p.Items),
-				errors.New(i.Title + ": Enclosure.URL is required")
+			return errors.New(i.Title + ": Enclosure.URL is required")
 		}
-		if i.Enclosure.TypeFormatted == enclosureDefault {
-			return len(p.Items),
-				errors.New(i.Title + ": Enclosure.Type is required")
+		if i.Enclosure.TypeFormatted == enclosureDefault ||
+			i.Enclosure.Type == UnknownEnclosureType && len(i.Enclosure.TypeFormatted) == 0 {
+			return errors.New(i.Title + ": Enclosure.Type is required")
 		}
 	} else if len(i.Link) == 0 {
-		return len(p.Items),
-			errors.New(i.Title + ": Link is required when not using Enclosure")
+		return errors.New(i.Title + ": Link is required when not using Enclosure")
+	}
+
+	for _, s := range i.Soundbites {
+		if err := i.validateSoundbite(s); err != nil {
+			return err
+		}
+		s.StartTimeFormatted = formatSeconds(s.StartTime)
+		s.DurationFormatted = formatSeconds(s.Duration)
 	}
 
 	// corrective actions and overrides
@@ -550,14 +719,16 @@ func (p *Podcast) AddItem(i Item) (int, error) {
 			i.Enclosure.Length = 0
 		}
 		i.Enclosure.LengthFormatted = strconv.FormatInt(i.Enclosure.Length, 10)
-		i.Enclosure.TypeFormatted = i.Enclosure.Type.String()
+		if i.Enclosure.Type != UnknownEnclosureType {
+			i.Enclosure.TypeFormatted = i.Enclosure.Type.String()
+		}
 
 		// allow Link to be set for article references to Downloads,
 		// otherwise set it to the enclosurer's URL.
 		if len(i.Link) == 0 {
 			i.Link = i.Enclosure.URL
 		}
-	} else {
+	} else if i.GUID == nil {
 		i.GUID = &GUID{IsPermaLink: true, Value: i.Link} // yep, GUID is the Permlink URL
 	}
 
@@ -580,9 +751,55 @@ func (p *Podcast) AddItem(i Item) (int, error) {
 			i.IImage = &IImage{HREF: p.Image.URL}
 		}
 	}
+	for _, t := range i.Transcripts {
+		if t.isCaptions() {
+			i.IIsClosedCaptioned = "Yes"
+		}
+	}
 
-	p.Items = append(p.Items, &i)
-	return len(p.Items), nil
+	return nil
+}
+
+// AddLiveItem announces a live stream of the show with a podcast:liveItem.
+// It returns a count of LiveItems added or any errors in validation.
+//
+// The embedded Item is validated and overridden as with AddItem, where the
+// Enclosure is the stream.  In addition, the GUID, Status and Start are
+// required.  Use the same GUID for the episode published from the recording.
+func (p *Podcast) AddLiveItem(li LiveItem) (int, error) {
+	if err := validateLiveItem(&li); err != nil {
+		return len(p.LiveItems), err
+	}
+	if err := p.prepareItem(&li.Item); err != nil {
+		return len(p.LiveItems), err
+	}
+
+	p.LiveItems = append(p.LiveItems, &li)
+	return len(p.LiveItems), nil
+}
+
+// PublishLiveItem turns the LiveItem with the guid into a regular Item once
+// its recording is available, replacing the stream with the recording
+// Enclosure.
+//
+// The Item is added with AddItem and the LiveItem is removed from the
+// Podcast.  It returns a count of Items or any errors in validation.
+func (p *Podcast) PublishLiveItem(guid string, recording Enclosure) (int, error) {
+	for n, li := range p.LiveItems {
+		if li.GUID == nil || li.GUID.Value != guid {
+			continue
+		}
+
+		i := li.ToItem()
+		i.Enclosure = &recording
+		added, err := p.AddItem(i)
+		if err != nil {
+			return added, err
+		}
+		p.LiveItems = append(p.LiveItems[:n], p.LiveItems[n+1:]...)
+		return added, nil
+	}
+	return len(p.Items), errors.New(guid + ": LiveItem not found")
 }
 
 func (p *Podcast) AddItunesBlock(block string) {
@@ -591,6 +808,37 @@ func (p *Podcast) AddItunesBlock(block string) {
 	} else {
 		p.IBlock = "No"
 	}
+	p.GooglePlayBlock = googlePlayYesNo(block == "hide")
+}
+
+// AddSpotifyLimit limits Spotify to showing the recentCount most recent
+// episodes of the podcast.  A recentCount of zero or less is ignored.
+func (p *Podcast) AddSpotifyLimit(recentCount int) {
+	if recentCount <= 0 {
+		return
+	}
+	p.SpotifyLimit = &SpotifyLimit{RecentCount: recentCount}
+}
+
+// AddSpotifyCountryOfOrigin sets the countries the podcast is intended for
+// with the spotify:countryOfOrigin tag, which Spotify uses to recommend it.
+//
+// Each country is an ISO 3166-1 alpha-2 code, e.g. "us" or "GB", and an
+// error is returned for unknown codes.  Calling it again replaces the
+// countries.
+func (p *Podcast) AddSpotifyCountryOfOrigin(countries ...string) error {
+	var codes []string
+	for _, country := range countries {
+		code, err := parseCountryCode(country)
+		if err != nil {
+			return err
+		}
+		if !containsString(codes, code) {
+			codes = append(codes, code)
+		}
+	}
+	p.SpotifyCountryOfOrigin = strings.Join(codes, " ")
+	return nil
 }
 
 func (p *Podcast) AddItunesComplete(complete string) {
@@ -634,6 +882,23 @@ func (p *Podcast) AddOwner(name, email string) {
 		Name:  GenerateFeedString(name),
 		Email: GenerateFeedString(email),
 	}
+	p.GooglePlayEmail = p.IOwner.Email
+}
+
+// AddPubDateTime sets the publish time of the feed, formatted as RFC 1123Z
+// when the feed is encoded.
+func (p *Podcast) AddPubDateTime(datetime time.Time) {
+	if datetime.IsZero() {
+		return
+	}
+
+	p.PubDateTime = datetime
+}
+
+// AddExplicit marks the Podcast as explicit, or clean.  It is the typed
+// alternative to AddParentalAdvisory, written "yes" or "no" alike.
+func (p *Podcast) AddExplicit(explicit bool) {
+	p.Explicit = &explicit
 }
 
 func (p *Podcast) AddPubDate(datetime string) {
@@ -689,23 +954,42 @@ func (p *Podcast) AddSummary(summary string) {
 // }
 
 // Encode writes the bytes to the io.Writer stream in RSS 2.0 specification.
+//
+// Items are written according to their State at the current time, see
+// EncodeWithOptions.
 func (p *Podcast) Encode(w io.Writer) error {
-	if _, err := w.Write([]byte("<?xml version=\"1.0\" encoding=\"UTF-8\"?>\n")); err != nil {
-		return errors.Wrap(err, "podcast.Encode: w.Write return error")
+	return p.EncodeWithOptions(w, EncodeOptions{})
+}
+
+// formatted returns a copy of the Podcast, and its Items, with the string
+// fields formatted from the typed fields that are set.  The Podcast itself
+// is left untouched so encoding it again gives the same output.
+func (p *Podcast) formatted() *Podcast {
+	e := *p
+	if !e.PubDateTime.IsZero() {
+		e.PubDate = e.PubDateTime.Format(time.RFC1123Z)
+	}
+	if !e.LastBuildDateTime.IsZero() {
+		e.LastBuildDate = e.LastBuildDateTime.Format(time.RFC1123Z)
+	}
+	if e.Explicit != nil {
+		e.IExplicit = googlePlayYesNo(*e.Explicit)
+		e.GooglePlayExplicit = e.IExplicit
 	}
 
-	// atomLink := ""
-	// if p.AtomLink != nil {
-	// 	atomLink = "http://www.w3.org/2005/Atom"
-	// }
-	wrapped := PodcastWrapper{
-		ITUNESNS: ITUNESNS,
-		CONTENT:  CONTENT,
-		// ATOMNS:   atomLink,
-		Version: "2.0",
-		Channel: p,
+	e.Items = make([]*Item, len(p.Items))
+	for n, i := range p.Items {
+		c := *i
+		c.format()
+		e.Items[n] = &c
+	}
+	e.LiveItems = make([]*LiveItem, len(p.LiveItems))
+	for n, li := range p.LiveItems {
+		c := *li
+		c.format()
+		e.LiveItems[n] = &c
 	}
-	return p.encode(w, wrapped)
+	return &e
 }
 
 // String encodes the Podcast state to a string.
@@ -728,13 +1012,15 @@ func (p *Podcast) String() string {
 // }
 
 type PodcastWrapper struct {
-	XMLName      xml.Name `xml:"rss"`
-	Version      string   `xml:"version,attr"`
-	ATOMNS       string   `xml:"xmlns:atom,attr,omitempty"`
-	ITUNESNS     string   `xml:"xmlns:itunes,attr"`
-	GOOGLEPLAYNS string   `xml:"xmlns:googleplay,attr"`
-	SPOTIFYNS    string   `xml:"xmlns:spotify,attr"`
-	CONTENT      string   `xml:"xmlns:content,attr"`
+	XMLName      xml.Name   `xml:"rss"`
+	Version      string     `xml:"version,attr"`
+	ATOMNS       string     `xml:"xmlns:atom,attr,omitempty"`
+	ITUNESNS     string     `xml:"xmlns:itunes,attr"`
+	GOOGLEPLAYNS string     `xml:"xmlns:googleplay,attr"`
+	SPOTIFYNS    string     `xml:"xmlns:spotify,attr"`
+	CONTENT      string     `xml:"xmlns:content,attr"`
+	PODCASTNS    string     `xml:"xmlns:podcast,attr,omitempty"`
+	Namespaces   []xml.Attr `xml:",any,attr"`
 	Channel      *Podcast
 }
 
@@ -745,11 +1031,29 @@ func NewWrapper(p *Podcast) PodcastWrapper {
 		GOOGLEPLAYNS: GOOGLEPLAYNS,
 		SPOTIFYNS:    SPOTIFYNS,
 		CONTENT:      CONTENT,
+		PODCASTNS:    PODCASTNS,
 		Version:      "2.0",
+		Namespaces:   namespaceAttrs(p.Namespaces),
 		Channel:      p,
 	}
 }
 
+// namespaceAttrs returns the declarations of the namespaces, sorted by
+// prefix.
+func namespaceAttrs(namespaces map[string]string) []xml.Attr {
+	if len(namespaces) == 0 {
+		return nil
+	}
+	attrs := make([]xml.Attr, 0, len(namespaces))
+	for prefix, uri := range namespaces {
+		attrs = append(attrs, xml.Attr{Name: xml.Name{Local: "xmlns:" + prefix}, Value: uri})
+	}
+	sort.Slice(attrs, func(a, b int) bool {
+		return attrs[a].Name.Local < attrs[b].Name.Local
+	})
+	return attrs
+}
+
 var encoder = func(w io.Writer, o interface{}) error {
 	e := xml.NewEncoder(w)
 	e.Indent("", "  ")
@@ -1,7 +1,12 @@
 package podcast_test
 
 import (
+	"bytes"
+	"encoding/xml"
 	"errors"
+	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
@@ -15,6 +20,48 @@ var (
 	pubDate     = createdDate.AddDate(0, 0, 3)
 )
 
+// newTestPodcast returns a Podcast with the required fields and, for each
+// title, an Item with an Enclosure.
+func newTestPodcast(t *testing.T, titles ...string) podcast.Podcast {
+	p := podcast.New("title", "http://example.com/", podcast.Description{Text: "Description"}, nil, nil)
+	for n, title := range titles {
+		i := podcast.Item{Title: title, Description: &podcast.Description{Text: "d"}}
+		i.AddEnclosureType("http://example.com/"+strconv.Itoa(n+1)+".mp3", podcast.MP3, 1)
+		if _, err := p.AddItem(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return p
+}
+
+// reencode encodes the Podcast and decodes the feed back, to check what
+// was written rather than the fields set.
+func reencode(t *testing.T, p *podcast.Podcast) *podcast.Podcast {
+	var b bytes.Buffer
+	if err := p.Encode(&b); err != nil {
+		t.Fatal(err)
+	}
+	d, err := podcast.Decode(&b)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return d
+}
+
+func TestNewDates(t *testing.T) {
+	t.Parallel()
+
+	// act
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, &createdDate, &updatedDate)
+	out := p.String()
+
+	// assert
+	assert.Equal(t, createdDate, p.PubDateTime)
+	assert.Equal(t, updatedDate, p.LastBuildDateTime)
+	assert.Contains(t, out, "<pubDate>Wed, 01 Feb 2017 08:21:52 +0000</pubDate>")
+	assert.Contains(t, out, "<lastBuildDate>Mon, 06 Feb 2017 08:21:52 +0000</lastBuildDate>")
+}
+
 func TestNewNonNils(t *testing.T) {
 	t.Parallel()
 
@@ -70,7 +117,7 @@ func TestAddAuthor(t *testing.T) {
 
 	// assert
 	// assert.Len(t, p.ManagingEditor, 0)
-	assert.Equal(t, p.IAuthor, "Joe, Lisa, Aaron&apos;s Woods")
+	assert.Equal(t, p.IAuthor, "Joe, Lisa, Aaron's Woods")
 }
 
 func TestAddCopyright(t *testing.T) {
@@ -83,7 +130,7 @@ func TestAddCopyright(t *testing.T) {
 	p.AddCopyright("inserted © text")
 
 	// assert
-	assert.Equal(t, p.Copyright, "inserted &#xA9; text")
+	assert.Equal(t, p.Copyright, "inserted © text")
 }
 
 func TestAddCopyrightEmpty(t *testing.T) {
@@ -112,6 +159,74 @@ func TestAddAtomLinkHrefEmpty(t *testing.T) {
 	assert.Nil(t, p.AtomLink)
 }
 
+func TestAddAtomLinkKeepsGUID(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+	p.AddAtomLink("https://example.com/feed.rss")
+	unset := p.GUID
+	guid := podcast.GenerateFeedGUID("https://example.com/feed.rss")
+	p.AddGUID(guid)
+
+	// act
+	p.AddAtomLink("https://new.example.com/feed.rss")
+	p.AddNewFeedURL("https://new.example.com/feed.rss")
+
+	// assert
+	assert.Len(t, unset, 0)
+	assert.Equal(t, guid, p.GUID)
+}
+
+func TestAddPodcastGUIDEmpty(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+
+	// act
+	p.AddGUID("")
+
+	// assert
+	assert.Len(t, p.GUID, 0)
+}
+
+func TestGenerateFeedGUIDStripsSchemeAndSlashes(t *testing.T) {
+	t.Parallel()
+
+	expected := "917393e3-1b1e-5cef-ace4-edaa54e1f810"
+
+	assert.Equal(t, expected, podcast.GenerateFeedGUID("https://mp3s.nashownotes.com/pc20rss.xml"))
+	assert.Equal(t, expected, podcast.GenerateFeedGUID("http://mp3s.nashownotes.com/pc20rss.xml"))
+	assert.Equal(t, expected, podcast.GenerateFeedGUID("mp3s.nashownotes.com/pc20rss.xml//"))
+}
+
+func TestAddLockedWithoutOwner(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+
+	// act
+	p.AddLocked(true, "")
+
+	// assert
+	assert.Nil(t, p.Locked)
+}
+
+func TestAddLockedNo(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+
+	// act
+	p.AddLocked(false, "")
+
+	// assert
+	assert.Equal(t, "no", p.Locked.Value)
+}
+
 func TestAddCategoryEmpty(t *testing.T) {
 	t.Parallel()
 
@@ -119,12 +234,134 @@ func TestAddCategoryEmpty(t *testing.T) {
 	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
 
 	// act
-	p.AddCategory("", nil)
+	err := p.AddCategory("", nil)
 
 	// assert
+	assert.Error(t, err)
 	assert.Len(t, p.ICategories, 0)
 }
 
+func TestAddCategoryInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name          string
+		category      string
+		subCategories []string
+	}{
+		{"unknown category", "mycat", nil},
+		{"unknown subcategory", "Arts", []string{"Books", "Physics"}},
+		{"lowercase category", "arts", nil},
+		{"retired subcategory of current category", "Science", []string{"Medicine"}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			p := newTestPodcast(t)
+
+			// act
+			err := p.AddCategory(tt.category, tt.subCategories)
+
+			// assert
+			assert.Error(t, err)
+			assert.Len(t, p.ICategories, 0)
+			assert.Len(t, p.GooglePlayCategories, 0)
+		})
+	}
+}
+
+func TestAddCategoryLegacy(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newTestPodcast(t)
+
+	// act
+	err1 := p.AddCategory("Technology", []string{"Podcasting", "Tech News"})
+	err2 := p.AddCategory("Games & Hobbies", []string{"Video Games", "Other Games"})
+	d := reencode(t, &p)
+
+	// assert
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Equal(t, []string{
+		"Leisure", "Leisure > Games", "Leisure > Video Games",
+		"News", "News > Tech News",
+		"Technology",
+	}, categoryPaths(d.ICategories))
+}
+
+func TestAddAppleCategory(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newTestPodcast(t)
+
+	// act
+	err1 := p.AddAppleCategory(podcast.CategoryTVAndFilm, podcast.CategoryTVAndFilmTVReviews)
+	err2 := p.AddAppleCategory(podcast.CategoryArts, podcast.CategoryArtsFood, podcast.CategoryArtsBooks)
+	err3 := p.AddAppleCategory(podcast.CategoryTVAndFilm, podcast.CategoryTVAndFilmAfterShows, podcast.CategoryTVAndFilmTVReviews)
+	err4 := p.AddAppleCategory(podcast.CategoryArts, podcast.CategoryNewsPolitics)
+	err5 := p.AddAppleCategory(podcast.Category("Cats"))
+	d := reencode(t, &p)
+
+	// assert
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.NoError(t, err3)
+	assert.Error(t, err4)
+	assert.Error(t, err5)
+	assert.Equal(t, []string{
+		"Arts", "Arts > Books", "Arts > Food",
+		"TV & Film", "TV & Film > After Shows", "TV & Film > TV Reviews",
+	}, categoryPaths(d.ICategories))
+}
+
+// categoryPaths lists the categories and their subcategories, in order.
+func categoryPaths(categories []*podcast.ICategory) []string {
+	var paths []string
+	for _, c := range categories {
+		paths = append(paths, c.Text)
+		for _, s := range c.ICategories {
+			paths = append(paths, c.Text+" > "+s.Text)
+		}
+	}
+	return paths
+}
+
+func TestLookupCategory(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		category, subcategory string
+		want, wantSub         podcast.Category
+	}{
+		{"Arts", "", podcast.CategoryArts, ""},
+		{"Arts", "Literature", podcast.CategoryArts, podcast.CategoryArtsBooks},
+		{"Health", "Alternative Health", podcast.CategoryHealthAndFitness, podcast.CategoryHealthAndFitnessAlternativeHealth},
+		{"Science & Medicine", "Medicine", podcast.CategoryHealthAndFitness, podcast.CategoryHealthAndFitnessMedicine},
+		{"Society & Culture", "History", podcast.CategoryHistory, ""},
+		{"Sports & Recreation", "Outdoor", podcast.CategorySports, podcast.CategorySportsWilderness},
+	}
+	for _, tt := range tests {
+		c, s, err := podcast.LookupCategory(tt.category, tt.subcategory)
+		assert.NoError(t, err, tt.category+" > "+tt.subcategory)
+		assert.Equal(t, tt.want, c, tt.category+" > "+tt.subcategory)
+		assert.Equal(t, tt.wantSub, s, tt.category+" > "+tt.subcategory)
+	}
+}
+
+func TestLookupCategoryEscaped(t *testing.T) {
+	t.Parallel()
+
+	_, _, err := podcast.LookupCategory("Religion &amp; Spirituality", "")
+
+	assert.Error(t, err)
+}
+
 func TestAddPodcastDescriptionEmpty(t *testing.T) {
 	t.Parallel()
 
@@ -166,11 +403,11 @@ func TestAddCategorySubCatEmpty1(t *testing.T) {
 	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
 
 	// act
-	p.AddCategory("mycat", []string{""})
+	p.AddCategory("Arts", []string{""})
 
 	// assert
 	assert.Len(t, p.ICategories, 1)
-	assert.Equal(t, p.ICategories[0].Text, "mycat")
+	assert.Equal(t, p.ICategories[0].Text, "Arts")
 	assert.Len(t, p.ICategories[0].ICategories, 0)
 }
 
@@ -181,12 +418,15 @@ func TestAddCategorySubCatEmpty2(t *testing.T) {
 	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
 
 	// act
-	p.AddCategory("mycat", []string{"xyz", "", "abc"})
+	p.AddCategory("Arts", []string{"Design", "", "Books"})
 
 	// assert
 	assert.Len(t, p.ICategories, 1)
-	assert.Equal(t, p.ICategories[0].Text, "mycat")
-	assert.Len(t, p.ICategories[0].ICategories, 2)
+	assert.Equal(t, p.ICategories[0].Text, "Arts")
+	if assert.Len(t, p.ICategories[0].ICategories, 2) {
+		assert.Equal(t, p.ICategories[0].ICategories[0].Text, "Books")
+		assert.Equal(t, p.ICategories[0].ICategories[1].Text, "Design")
+	}
 }
 
 func TestParseCategories(t *testing.T) {
@@ -195,11 +435,24 @@ func TestParseCategories(t *testing.T) {
 	out := podcast.ParseCategories([]string{"Arts", "Books", "Religion & Spirituality", "Christianity", "Buddhism", "Sports", "Health & Fitness", "Documentary"})
 
 	expected := map[string][]string{
-		"Arts":                        []string{"Books"},
-		"Religion &amp; Spirituality": []string{"Christianity", "Buddhism"},
-		"Sports":                      []string{},
-		"Health &amp; Fitness":        []string{},
-		"Society &amp; Culture":       []string{"Documentary"},
+		"Arts":                    []string{"Books"},
+		"Religion & Spirituality": []string{"Christianity", "Buddhism"},
+		"Sports":                  []string{},
+		"Health & Fitness":        []string{},
+		"Society & Culture":       []string{"Documentary"},
+	}
+
+	assert.EqualValues(t, expected, out)
+}
+
+func TestParseCategoriesUnknown(t *testing.T) {
+	t.Parallel()
+
+	out := podcast.ParseCategories([]string{"Books", "Bombay", "Games & Hobbies", "Arts"})
+
+	expected := map[string][]string{
+		"Arts":    []string{"Books"},
+		"Leisure": []string{},
 	}
 
 	assert.EqualValues(t, expected, out)
@@ -211,7 +464,7 @@ func TestParseCategoriesChildCatEmpty(t *testing.T) {
 	out := podcast.ParseCategories([]string{"Health & Fitness"})
 
 	expected := map[string][]string{
-		"Health &amp; Fitness": []string{},
+		"Health & Fitness": []string{},
 	}
 
 	assert.EqualValues(t, expected, out)
@@ -303,6 +556,139 @@ func TestAddParentalAdvisoryClean(t *testing.T) {
 	assert.EqualValues(t, p.IExplicit, "no")
 }
 
+func TestAddParentalAdvisoryGooglePlay(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newTestPodcast(t, "Episode 1")
+
+	// act
+	p.AddParentalAdvisory(podcast.ParentalAdvisoryExplicit)
+	p.Items[0].AddParentalAdvisory(podcast.ParentalAdvisoryClean)
+	d := reencode(t, &p)
+
+	// assert
+	assert.Equal(t, "yes", d.GooglePlayExplicit)
+	assert.Equal(t, "no", d.Items[0].GooglePlayExplicit)
+}
+
+func TestAddItunesBlockGooglePlay(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newTestPodcast(t, "Episode 1")
+
+	// act
+	p.AddItunesBlock("no")
+	p.Items[0].AddItunesBlock("hide")
+	d := reencode(t, &p)
+
+	// assert
+	assert.Equal(t, "No", d.IBlock)
+	assert.Equal(t, "no", d.GooglePlayBlock)
+	assert.Equal(t, "Yes", d.Items[0].IBlock)
+	assert.Equal(t, "yes", d.Items[0].GooglePlayBlock)
+}
+
+func TestAddCategoryGooglePlay(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newTestPodcast(t)
+
+	// act
+	p.AddCategory("Science", []string{"Physics"})
+	p.AddCategory("Health & Fitness", nil)
+	p.AddCategory("Science & Medicine", nil)
+	p.AddCategory("mycat", nil)
+	d := reencode(t, &p)
+
+	// assert
+	assert.Len(t, d.ICategories, 2)
+	assert.Equal(t, []*podcast.GooglePlayCategory{
+		{XMLName: xml.Name{Local: "googleplay:category"}, Text: "Health"},
+		{XMLName: xml.Name{Local: "googleplay:category"}, Text: "Science & Medicine"},
+	}, d.GooglePlayCategories)
+}
+
+func TestAddSpotifyLimitEmpty(t *testing.T) {
+	t.Parallel()
+
+	p := newTestPodcast(t)
+
+	p.AddSpotifyLimit(0)
+
+	assert.Nil(t, p.SpotifyLimit)
+}
+
+func TestAddSpotifyCountryOfOrigin(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newTestPodcast(t)
+
+	// act
+	p.AddSpotifyLimit(10)
+	err := p.AddSpotifyCountryOfOrigin("US", " ca", "uk", "us")
+	d := reencode(t, &p)
+
+	// assert
+	assert.NoError(t, err)
+	if assert.NotNil(t, d.SpotifyLimit) {
+		assert.Equal(t, 10, d.SpotifyLimit.RecentCount)
+	}
+	assert.Equal(t, "us ca gb", d.SpotifyCountryOfOrigin)
+}
+
+func TestAddSpotifyCountryOfOriginInvalid(t *testing.T) {
+	t.Parallel()
+
+	for _, country := range []string{"", "usa", "zz", "eu", "419", "12"} {
+		p := newTestPodcast(t)
+		p.SpotifyCountryOfOrigin = "fr"
+
+		err := p.AddSpotifyCountryOfOrigin("us", country)
+
+		assert.Error(t, err, country)
+		assert.Equal(t, "fr", p.SpotifyCountryOfOrigin, country)
+	}
+}
+
+func TestEncodeNamespaces(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newTestPodcast(t)
+
+	// act
+	d := xml.NewDecoder(strings.NewReader(p.String()))
+	namespaces := map[string]string{}
+	for {
+		tok, err := d.Token()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if rss, ok := tok.(xml.StartElement); ok {
+			for _, a := range rss.Attr {
+				if a.Name.Space == "xmlns" {
+					namespaces[a.Name.Local] = a.Value
+				}
+			}
+			break
+		}
+	}
+
+	// assert
+	assert.Equal(t, map[string]string{
+		"atom":       podcast.ATOMNS,
+		"itunes":     podcast.ITUNESNS,
+		"googleplay": podcast.GOOGLEPLAYNS,
+		"spotify":    podcast.SPOTIFYNS,
+		"content":    podcast.CONTENT,
+		"podcast":    podcast.PODCASTNS,
+	}, namespaces)
+}
+
 func TestAddImageEmpty(t *testing.T) {
 	t.Parallel()
 
@@ -330,6 +716,124 @@ func TestAddImage(t *testing.T) {
 	assert.Equal(t, p.IImage.HREF, "https://google.com")
 }
 
+func TestAddPersonInvalid(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		person podcast.Person
+		err    string
+	}{
+		{"missing name", podcast.Person{Role: "host"}, "Name is required"},
+		{"unknown role", podcast.Person{Name: "Jane", Role: "mascot"}, "unknown person role"},
+		{"unknown group", podcast.Person{Name: "Jane", Group: "band"}, "unknown person group"},
+		{"role outside group", podcast.Person{Name: "Jane", Role: "Host", Group: "Writing"}, "not part of group"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+
+			// act
+			err := p.AddPerson(tt.person)
+
+			// assert
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+			assert.Len(t, p.Persons, 0)
+			assert.Len(t, p.IAuthor, 0)
+		})
+	}
+}
+
+func TestAddPersonNonHostKeepsAuthor(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+	p.AddAuthor([]string{"Jane"})
+
+	// act
+	err := p.AddPerson(podcast.Person{Name: "Joe", Role: podcast.PersonRoleProducer})
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Jane", p.IAuthor)
+}
+
+func TestAddFundingEmpty(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+
+	// act
+	p.AddFunding("", "Support the show")
+
+	// assert
+	assert.Len(t, p.Funding, 0)
+}
+
+func TestAddFundingTextTooLong(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+	text := ""
+	for len(text) < 150 {
+		text += "support "
+	}
+
+	// act
+	p.AddFunding("https://example.com/donate", text)
+
+	// assert
+	assert.Len(t, p.Funding, 1)
+	assert.Len(t, p.Funding[0].Text, 128)
+}
+
+func TestAddValueInvalid(t *testing.T) {
+	t.Parallel()
+
+	node := func(split int, fee bool) *podcast.ValueRecipient {
+		return &podcast.ValueRecipient{Type: podcast.RecipientTypeNode, Address: "03ae9f91a0cb8ff43840e3c322c4c61f019d8c1c3cea15a25cfc425ac605e61a4a", Split: split, Fee: fee}
+	}
+	tests := []struct {
+		name  string
+		value podcast.Value
+		err   string
+	}{
+		{"missing type", podcast.Value{Method: podcast.ValueMethodKeysend, Recipients: []*podcast.ValueRecipient{node(1, false)}}, "Type and Value.Method are required"},
+		{"no recipients", podcast.Value{Type: podcast.ValueTypeLightning, Method: podcast.ValueMethodKeysend}, "at least one recipient"},
+		{"nil recipient", podcast.Value{Type: podcast.ValueTypeLightning, Method: podcast.ValueMethodKeysend, Recipients: []*podcast.ValueRecipient{nil}}, "recipient is nil"},
+		{"missing address", podcast.Value{Type: podcast.ValueTypeLightning, Method: podcast.ValueMethodKeysend, Recipients: []*podcast.ValueRecipient{{Type: podcast.RecipientTypeNode, Split: 1}}}, "Address are required"},
+		{"zero split", podcast.Value{Type: podcast.ValueTypeLightning, Method: podcast.ValueMethodKeysend, Recipients: []*podcast.ValueRecipient{node(0, false)}}, "greater than zero"},
+		{"only fees", podcast.Value{Type: podcast.ValueTypeLightning, Method: podcast.ValueMethodKeysend, Recipients: []*podcast.ValueRecipient{node(1, true)}}, "not a fee"},
+		{"fees too high", podcast.Value{Type: podcast.ValueTypeLightning, Method: podcast.ValueMethodKeysend, Recipients: []*podcast.ValueRecipient{node(1, false), node(100, true)}}, "less than 100 percent"},
+		{"custom value without key", podcast.Value{Type: podcast.ValueTypeLightning, Method: podcast.ValueMethodKeysend, Recipients: []*podcast.ValueRecipient{{Type: podcast.RecipientTypeNode, Address: "abc", Split: 1, CustomValue: "x"}}}, "CustomKey is required"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+
+			// act
+			err := p.AddValue(tt.value)
+
+			// assert
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+			assert.Len(t, p.Values, 0)
+		})
+	}
+}
+
 func TestAddItemEmptyTitleDescription(t *testing.T) {
 	t.Parallel()
 
@@ -558,6 +1062,208 @@ func TestAddItemRootIAuthorSetsAuthorIAuthor(t *testing.T) {
 	assert.EqualValues(t, "me@janedoe.com", p.Items[0].IAuthor)
 }
 
+func TestAddItemCaptionsTranscriptSetsClosedCaptioned(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+	i := podcast.Item{Title: "title", Description: &podcast.Description{Text: "desc"}, Link: "http://a.co/"}
+	i.Transcripts = []*podcast.Transcript{
+		{URL: "http://a.co/1.vtt", Type: podcast.TranscriptTypeVTT, Rel: podcast.TranscriptRelCaptions},
+	}
+
+	// act
+	_, err := p.AddItem(i)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "Yes", p.Items[0].IIsClosedCaptioned)
+}
+
+func TestAddItemSoundbiteOutsideDuration(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+	i := podcast.Item{Title: "title", Description: &podcast.Description{Text: "desc"}, Link: "http://a.co/"}
+	_ = i.AddSoundbite(90*time.Second, 30*time.Second, "too late")
+	i.AddDuration(100)
+
+	// act
+	added, err := p.AddItem(i)
+
+	// assert
+	assert.EqualValues(t, 0, added)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Soundbite ends after the episode duration")
+}
+
+func TestAddItemAlternateEnclosureFallback(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+	i := podcast.Item{Title: "title", Description: &podcast.Description{Text: "desc"}}
+	_ = i.AddAlternateEnclosure(podcast.AlternateEnclosure{
+		Type:    "audio/mpeg",
+		Length:  42,
+		Default: true,
+		Sources: []*podcast.Source{{URI: "ipfs://QmdwGqd3d2gFPGeJNLLCshdiPert45fMu84552Y4XHTy4y"}, {URI: "https://example.com/1.mp3"}},
+	})
+
+	// act
+	added, err := p.AddItem(i)
+
+	// assert
+	assert.EqualValues(t, 1, added)
+	assert.NoError(t, err)
+	assert.Equal(t, "https://example.com/1.mp3", p.Items[0].Enclosure.URL)
+	assert.Equal(t, "42", p.Items[0].Enclosure.LengthFormatted)
+	assert.Equal(t, "audio/mpeg", p.Items[0].Enclosure.TypeFormatted)
+}
+
+func TestAddItemAlternateEnclosureNoFallback(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+	i := podcast.Item{Title: "title", Description: &podcast.Description{Text: "desc"}}
+	_ = i.AddAlternateEnclosure(podcast.AlternateEnclosure{
+		Type:    "audio/mpeg",
+		Default: true,
+		Sources: []*podcast.Source{{URI: "ipfs://QmdwGqd3d2gFPGeJNLLCshdiPert45fMu84552Y4XHTy4y"}},
+	})
+
+	// act
+	added, err := p.AddItem(i)
+
+	// assert
+	assert.EqualValues(t, 0, added)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "Link is required")
+}
+
+func TestAddLiveItemInvalid(t *testing.T) {
+	t.Parallel()
+
+	start := time.Date(2021, time.September, 26, 7, 30, 0, 0, time.UTC)
+	stream := &podcast.Enclosure{URL: "https://example.com/live.mp3", Type: podcast.MP3, TypeFormatted: "audio/mpeg"}
+	guid := &podcast.GUID{Value: "live-1"}
+	tests := []struct {
+		name string
+		li   podcast.LiveItem
+		err  string
+	}{
+		{"missing guid", podcast.LiveItem{Status: "live", Start: start, Item: podcast.Item{Title: "t", Enclosure: stream}}, "GUID is required"},
+		{"unknown status", podcast.LiveItem{Status: "soon", Start: start, Item: podcast.Item{Title: "t", GUID: guid, Enclosure: stream}}, "unknown LiveItem.Status"},
+		{"missing start", podcast.LiveItem{Status: "live", Item: podcast.Item{Title: "t", GUID: guid, Enclosure: stream}}, "Start is required"},
+		{"end before start", podcast.LiveItem{Status: "live", Start: start, End: start.Add(-time.Hour), Item: podcast.Item{Title: "t", GUID: guid, Enclosure: stream}}, "End is before"},
+		{"missing stream", podcast.LiveItem{Status: "live", Start: start, Item: podcast.Item{Title: "t", GUID: guid, Link: "http://a.co/"}}, "Enclosure is required"},
+		{"missing title", podcast.LiveItem{Status: "live", Start: start, Item: podcast.Item{GUID: guid, Enclosure: stream}}, "Title"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			p := newTestPodcast(t)
+
+			// act
+			added, err := p.AddLiveItem(tt.li)
+
+			// assert
+			assert.EqualValues(t, 0, added)
+			assert.Error(t, err)
+			assert.Contains(t, err.Error(), tt.err)
+		})
+	}
+}
+
+func TestAddLiveItemEncoded(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newTestPodcast(t, "Episode 1")
+	li := podcast.LiveItem{
+		Status: podcast.LiveItemStatusLive,
+		Start:  time.Date(2021, time.September, 26, 7, 30, 0, 0, time.UTC),
+	}
+	li.AddTitle("Live")
+	li.AddGUID("live-1")
+	li.AddEnclosureType("https://example.com/live.mp3", podcast.MP3, 0)
+	li.AddContentLink("https://example.com/watch", "Watch")
+
+	// act
+	_, err := p.AddLiveItem(li)
+	d := reencode(t, &p)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, d.Items, 1)
+	if !assert.Len(t, d.LiveItems, 1) {
+		return
+	}
+	assert.Equal(t, podcast.LiveItemStatusLive, d.LiveItems[0].Status)
+	assert.Equal(t, li.Start, d.LiveItems[0].Start.UTC())
+	assert.True(t, d.LiveItems[0].End.IsZero())
+	assert.Equal(t, "live-1", d.LiveItems[0].GUID.Value)
+	assert.Equal(t, "https://example.com/live.mp3", d.LiveItems[0].Enclosure.URL)
+	assert.Equal(t, []*podcast.ContentLink{{
+		XMLName: xml.Name{Local: "podcast:contentLink"},
+		Href:    "https://example.com/watch",
+		Text:    "Watch",
+	}}, d.LiveItems[0].ContentLinks)
+}
+
+func TestPublishLiveItemEncoded(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newTestPodcast(t, "Episode 1")
+	li := podcast.LiveItem{
+		Status: podcast.LiveItemStatusEnded,
+		Start:  time.Date(2021, time.September, 26, 7, 30, 0, 0, time.UTC),
+	}
+	li.AddTitle("Live")
+	li.AddGUID("live-1")
+	li.AddEnclosureType("https://example.com/live.mp3", podcast.MP3, 0)
+	if _, err := p.AddLiveItem(li); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	added, err := p.PublishLiveItem("live-1", podcast.Enclosure{URL: "https://example.com/2.mp3", Type: podcast.MP3, Length: 2})
+	d := reencode(t, &p)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 2, added)
+	assert.Empty(t, d.LiveItems)
+	if !assert.Len(t, d.Items, 2) {
+		return
+	}
+	assert.Equal(t, "live-1", d.Items[1].GUID.Value)
+	assert.Equal(t, "https://example.com/2.mp3", d.Items[1].Link)
+	assert.Equal(t, li.Start, d.Items[1].PubDateTime.UTC())
+	assert.EqualValues(t, 2, d.Items[1].Enclosure.Length)
+}
+
+func TestPublishLiveItemNotFound(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newTestPodcast(t)
+
+	// act
+	added, err := p.PublishLiveItem("missing", podcast.Enclosure{URL: "https://example.com/1.mp3", Type: podcast.MP3})
+
+	// assert
+	assert.EqualValues(t, 0, added)
+	assert.Error(t, err)
+	assert.Contains(t, err.Error(), "LiveItem not found")
+}
+
 func TestAddBlockEmpty(t *testing.T) {
 	t.Parallel()
 
@@ -782,7 +1488,7 @@ func TestAddPodcastTitle(t *testing.T) {
 	p := podcast.Podcast{}
 	p.AddTitle("Podpal™, Inc. 20")
 
-	assert.Equal(t, p.Title, "Podpal&#x2122;, Inc. 20")
+	assert.Equal(t, p.Title, "Podpal™, Inc. 20")
 }
 
 func TestEncodeWriterError(t *testing.T) {
@@ -798,3 +1504,268 @@ func TestEncodeWriterError(t *testing.T) {
 	assert.Error(t, err)
 	assert.Contains(t, err.Error(), "w.Write return error")
 }
+
+func newCRUDTestPodcast(t *testing.T) podcast.Podcast {
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+	p.AddAuthor([]string{"Jane"})
+	p.AddImage("http://example.com/i.jpg")
+	for _, n := range []string{"1", "2", "3"} {
+		i := podcast.Item{Title: "Episode " + n, Description: &podcast.Description{Text: "d"}}
+		i.AddEnclosure("http://example.com/"+n+".mp3", podcast.MP3, "", 1)
+		if _, err := p.AddItem(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return p
+}
+
+func TestFindItem(t *testing.T) {
+	t.Parallel()
+
+	p := newCRUDTestPodcast(t)
+
+	i := p.FindItem("http://example.com/2.mp3")
+
+	if assert.NotNil(t, i) {
+		assert.Equal(t, "Episode 2", i.Title)
+	}
+	assert.Nil(t, p.FindItem("http://example.com/4.mp3"))
+}
+
+func TestUpdateItem(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newCRUDTestPodcast(t)
+	i := podcast.Item{Title: "Episode 2 (fixed)", Description: &podcast.Description{Text: "d"}}
+	i.AddEnclosure("http://example.com/2.mp3", podcast.MP3, "", 2)
+
+	// act
+	err := p.UpdateItem(i)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Len(t, p.Items, 3)
+	assert.Equal(t, "Episode 2 (fixed)", p.Items[1].Title)
+	assert.Equal(t, "http://example.com/2.mp3", p.Items[1].GUID.Value)
+	assert.Equal(t, "2", p.Items[1].Enclosure.LengthFormatted)
+	assert.Equal(t, "Jane", p.Items[1].IAuthor)
+	assert.Equal(t, "http://example.com/i.jpg", p.Items[1].IImage.HREF)
+}
+
+func TestUpdateItemInvalid(t *testing.T) {
+	t.Parallel()
+
+	p := newCRUDTestPodcast(t)
+	missing := podcast.Item{Title: "Episode 4", Description: &podcast.Description{Text: "d"}}
+	missing.AddEnclosure("http://example.com/4.mp3", podcast.MP3, "", 1)
+
+	assert.EqualError(t, p.UpdateItem(missing), "http://example.com/4.mp3: Item not found")
+	assert.Error(t, p.UpdateItem(podcast.Item{}))
+	assert.Len(t, p.Items, 3)
+}
+
+func TestUpsertItem(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newCRUDTestPodcast(t)
+	update := podcast.Item{Title: "Episode 1 (fixed)", Description: &podcast.Description{Text: "d"}}
+	update.AddEnclosure("http://example.com/1.mp3", podcast.MP3, "", 1)
+	insert := podcast.Item{Title: "Episode 4", Description: &podcast.Description{Text: "d"}}
+	insert.AddEnclosure("http://example.com/4.mp3", podcast.MP3, "", 1)
+
+	// act
+	n1, err1 := p.UpsertItem(update)
+	n2, err2 := p.UpsertItem(insert)
+
+	// assert
+	assert.NoError(t, err1)
+	assert.NoError(t, err2)
+	assert.Equal(t, 3, n1)
+	assert.Equal(t, 4, n2)
+	assert.Equal(t, "Episode 1 (fixed)", p.Items[0].Title)
+	assert.Equal(t, "Episode 4", p.Items[3].Title)
+}
+
+func TestAddItemDuplicateGUIDMerge(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newCRUDTestPodcast(t)
+	p.DuplicateGUIDs = podcast.DuplicateGUIDMerge
+	p.Items[0].Category = "News"
+	p.Items[0].AddSeason(2)
+	i := podcast.Item{Title: "Episode 1 (again)"}
+	i.AddEnclosure("http://example.com/1.mp3", podcast.MP3, "", 2)
+
+	// act
+	n, err := p.AddItem(i)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, 3, n)
+	assert.Equal(t, "Episode 1 (again)", p.Items[0].Title)
+	assert.Equal(t, "2", p.Items[0].Enclosure.LengthFormatted)
+	assert.Equal(t, "d", p.Items[0].Description.Text)
+	assert.Equal(t, "News", p.Items[0].Category)
+	assert.Equal(t, 2, p.Items[0].Season)
+}
+
+func TestAddItemDuplicateGUIDMergeEveryField(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+	p.IAuthor = "podcast author"
+	p.AddImage("http://example.com/podcast.jpg")
+	p.DuplicateGUIDs = podcast.DuplicateGUIDMerge
+	existing := podcast.Item{}
+	fillFields(reflect.ValueOf(&existing).Elem(), 1)
+	existing.AddGUID("episode-1")
+	existing.State = podcast.ItemStateDraft
+	existing.Enclosure = &podcast.Enclosure{URL: "http://example.com/1.mp3", Type: podcast.MP3, Length: 1}
+	existing.AlternateEnclosures = []*podcast.AlternateEnclosure{{
+		Type:    "audio/opus",
+		Sources: []*podcast.Source{{URI: "http://example.com/1.opus"}},
+	}}
+	existing.Duration = time.Hour
+	existing.Soundbites = []*podcast.Soundbite{{Duration: time.Minute}}
+	p.Items = []*podcast.Item{&existing}
+	i := podcast.Item{Title: "Episode 1 (again)", Link: "http://example.com/again"}
+	i.AddGUID("episode-1")
+
+	// act
+	_, err := p.AddItem(i)
+
+	// assert
+	if !assert.NoError(t, err) || !assert.Len(t, p.Items, 1) {
+		return
+	}
+	merged := reflect.ValueOf(p.Items[0]).Elem()
+	want := reflect.ValueOf(existing)
+	for n := 0; n < merged.NumField(); n++ {
+		name := merged.Type().Field(n).Name
+		switch name {
+		case "XMLName":
+		case "Title", "Link", "GUID":
+			assert.Equal(t, reflect.ValueOf(i).Field(n).Interface(), merged.Field(n).Interface(), name)
+		case "State":
+			assert.Equal(t, podcast.ItemStatePublished, p.Items[0].State)
+		default:
+			assert.False(t, want.Field(n).IsZero(), name+" is not filled")
+			assert.Equal(t, want.Field(n).Interface(), merged.Field(n).Interface(), name)
+		}
+	}
+}
+
+// fillFields sets every exported field of the struct to a non-zero value,
+// filling the structs it points to as well down to the depth.
+func fillFields(v reflect.Value, depth int) {
+	for n := 0; n < v.NumField(); n++ {
+		f := v.Field(n)
+		if !f.CanSet() || f.Type() == reflect.TypeOf(xml.Name{}) {
+			continue
+		}
+		fillValue(f, v.Type().Field(n).Name, int64(n+1), depth)
+	}
+}
+
+// fillValue sets the value to a non-zero one derived from the name and n.
+func fillValue(f reflect.Value, name string, n int64, depth int) {
+	switch f.Kind() {
+	case reflect.String:
+		f.SetString(name)
+	case reflect.Bool:
+		f.SetBool(true)
+	case reflect.Int, reflect.Int64:
+		f.SetInt(n)
+	case reflect.Float64:
+		f.SetFloat(float64(n) / 10)
+	case reflect.Ptr:
+		f.Set(reflect.New(f.Type().Elem()))
+		if depth > 1 {
+			fillValue(f.Elem(), name, n, depth-1)
+		}
+	case reflect.Slice:
+		f.Set(reflect.MakeSlice(f.Type(), 1, 1))
+		fillValue(f.Index(0), name, n, depth)
+	case reflect.Map:
+		if depth > 1 {
+			f.Set(reflect.MakeMap(f.Type()))
+			k, e := reflect.New(f.Type().Key()).Elem(), reflect.New(f.Type().Elem()).Elem()
+			fillValue(k, strings.ToLower(name), n, depth-1)
+			fillValue(e, name, n, depth-1)
+			f.SetMapIndex(k, e)
+		}
+	case reflect.Struct:
+		if f.Type() == reflect.TypeOf(time.Time{}) {
+			f.Set(reflect.ValueOf(createdDate))
+		} else if depth > 1 {
+			fillFields(f, depth-1)
+		}
+	}
+}
+
+func TestAddItemWithoutEnclosureGUIDSet(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("title", "link", podcast.Description{Text: "Description"}, nil, nil)
+	i := podcast.Item{Title: "title", Link: "http://example.com/post", Description: &podcast.Description{Text: "desc"}}
+	i.AddGUID("post-1")
+
+	// act
+	_, err := p.AddItem(i)
+
+	// assert
+	assert.NoError(t, err)
+	assert.Equal(t, "post-1", p.Items[0].GUID.Value)
+	assert.NotNil(t, p.FindItem("post-1"))
+	assert.NoError(t, p.RemoveItem("post-1"))
+}
+
+func TestRemoveItem(t *testing.T) {
+	t.Parallel()
+
+	p := newCRUDTestPodcast(t)
+
+	err := p.RemoveItem("http://example.com/2.mp3")
+
+	assert.NoError(t, err)
+	if assert.Len(t, p.Items, 2) {
+		assert.Equal(t, "Episode 1", p.Items[0].Title)
+		assert.Equal(t, "Episode 3", p.Items[1].Title)
+	}
+	assert.EqualError(t, p.RemoveItem("http://example.com/2.mp3"), "http://example.com/2.mp3: Item not found")
+}
+
+func TestAddItemDuplicateGUID(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		policy podcast.DuplicateGUIDPolicy
+		count  int
+		err    bool
+		title  string
+	}{
+		{podcast.DuplicateGUIDAllow, 4, false, "Episode 1"},
+		{podcast.DuplicateGUIDReject, 3, true, "Episode 1"},
+		{podcast.DuplicateGUIDMerge, 3, false, "Episode 1 (again)"},
+	}
+	for _, tt := range tests {
+		// arrange
+		p := newCRUDTestPodcast(t)
+		p.DuplicateGUIDs = tt.policy
+		i := podcast.Item{Title: "Episode 1 (again)", Description: &podcast.Description{Text: "d"}}
+		i.AddEnclosure("http://example.com/1.mp3", podcast.MP3, "", 1)
+
+		// act
+		n, err := p.AddItem(i)
+
+		// assert
+		assert.Equal(t, tt.err, err != nil, "policy %d", tt.policy)
+		assert.Equal(t, tt.count, n, "policy %d", tt.policy)
+		assert.Equal(t, tt.title, p.Items[0].Title, "policy %d", tt.policy)
+	}
+}
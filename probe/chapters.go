@@ -0,0 +1,207 @@
+package probe
+
+import (
+	"encoding/binary"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Chapter is a chapter embedded in a media file, from the ID3 CHAP frames of
+// an MP3 file or the Nero chpl atom of an MP4 file.
+type Chapter struct {
+	Start time.Duration
+	End   time.Duration
+
+	Title string
+
+	// URL linked to by the chapter, from the WXXX frame of a CHAP frame.
+	URL string
+
+	// Image of the chapter, from the APIC frame of a CHAP frame.
+	Image *Artwork
+
+	// Hidden marks a chapter the CTOC frames of an MP3 file leave out of
+	// the table of contents.
+	Hidden bool
+}
+
+// ctocTopLevel is the CTOC flag of the top-level table of contents.
+const ctocTopLevel = 0x02
+
+// ctoc is an ID3 table of contents.
+type ctoc struct {
+	topLevel bool
+	children []string
+}
+
+// chapters returns the chapters of the CHAP frames of the tag, sorted by
+// start time.  Chapters no CTOC frame reaches from the top-level one are
+// Hidden.
+func (t *id3Tag) chapters() []*Chapter {
+	chapters := map[string]*Chapter{}
+	var ids []string
+	tocs := map[string]*ctoc{}
+	for _, f := range t.Frames {
+		switch f.ID {
+		case "CHAP":
+			id, c := t.parseCHAP(f.Data)
+			if c != nil {
+				if _, ok := chapters[id]; !ok {
+					ids = append(ids, id)
+				}
+				chapters[id] = c
+			}
+		case "CTOC":
+			id, c := parseCTOC(f.Data)
+			if c != nil {
+				tocs[id] = c
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return nil
+	}
+
+	if len(tocs) > 0 {
+		listed := map[string]bool{}
+		var visit func(id string)
+		visit = func(id string) {
+			if listed[id] {
+				return
+			}
+			listed[id] = true
+			if toc, ok := tocs[id]; ok {
+				for _, child := range toc.children {
+					visit(child)
+				}
+			}
+		}
+		hasTopLevel := false
+		for id, toc := range tocs {
+			if toc.topLevel {
+				hasTopLevel = true
+				visit(id)
+			}
+		}
+		if !hasTopLevel {
+			for id := range tocs {
+				visit(id)
+			}
+		}
+		for id, c := range chapters {
+			c.Hidden = !listed[id]
+		}
+	}
+
+	sorted := make([]*Chapter, len(ids))
+	for n, id := range ids {
+		sorted[n] = chapters[id]
+	}
+	sort.SliceStable(sorted, func(a, b int) bool {
+		return sorted[a].Start < sorted[b].Start
+	})
+	return sorted
+}
+
+// parseCHAP returns the element id and the Chapter of a CHAP frame.
+func (t *id3Tag) parseCHAP(b []byte) (string, *Chapter) {
+	id, rest := splitTerminated(id3EncodingLatin1, b)
+	if len(rest) < 16 {
+		return "", nil
+	}
+	c := &Chapter{
+		Start: time.Duration(binary.BigEndian.Uint32(rest)) * time.Millisecond,
+		End:   time.Duration(binary.BigEndian.Uint32(rest[4:])) * time.Millisecond,
+	}
+	sub := &id3Tag{Version: t.Version, Frames: parseID3Frames(rest[16:], t.Version)}
+	c.Title = sub.title()
+	c.URL = sub.url()
+	c.Image = sub.artwork()
+	return string(id), c
+}
+
+// parseCTOC returns the element id and the table of contents of a CTOC
+// frame.
+func parseCTOC(b []byte) (string, *ctoc) {
+	id, rest := splitTerminated(id3EncodingLatin1, b)
+	if len(rest) < 2 {
+		return "", nil
+	}
+	toc := &ctoc{topLevel: rest[0]&ctocTopLevel != 0}
+	count := int(rest[1])
+	rest = rest[2:]
+	for n := 0; n < count && len(rest) > 0; n++ {
+		var child []byte
+		child, rest = splitTerminated(id3EncodingLatin1, rest)
+		toc.children = append(toc.children, string(child))
+	}
+	return string(id), toc
+}
+
+// url returns the url of the WXXX frame of the tag, or of another URL link
+// frame.
+func (t *id3Tag) url() string {
+	if f := t.frame("WXXX", "WXX"); f != nil && len(f.Data) > 0 {
+		_, u := splitTerminated(f.Data[0], f.Data[1:])
+		return decodeID3Text(id3EncodingLatin1, u)
+	}
+	for _, f := range t.Frames {
+		if strings.HasPrefix(f.ID, "W") {
+			return decodeID3Text(id3EncodingLatin1, f.Data)
+		}
+	}
+	return ""
+}
+
+// parseCHPL returns the chapters of a Nero chpl atom, whose start times are
+// in units of 100 nanoseconds.
+func parseCHPL(p []byte) []*Chapter {
+	if len(p) < 5 {
+		return nil
+	}
+	version := p[0]
+	p = p[4:]
+	if version == 1 {
+		if len(p) < 5 {
+			return nil
+		}
+		p = p[4:]
+	}
+	count := int(p[0])
+	p = p[1:]
+
+	var chapters []*Chapter
+	for n := 0; n < count && len(p) >= 9; n++ {
+		start := binary.BigEndian.Uint64(p)
+		l := int(p[8])
+		p = p[9:]
+		if len(p) < l {
+			break
+		}
+		chapters = append(chapters, &Chapter{
+			Start: time.Duration(start) * 100,
+			Title: string(p[:l]),
+		})
+		p = p[l:]
+	}
+	sort.SliceStable(chapters, func(a, b int) bool {
+		return chapters[a].Start < chapters[b].Start
+	})
+	return chapters
+}
+
+// endChapters sets the End of the chapters without one to the Start of the
+// next chapter, or to the end of the media for the last one.
+func endChapters(chapters []*Chapter, duration time.Duration) {
+	for n, c := range chapters {
+		if c.End > c.Start {
+			continue
+		}
+		if n+1 < len(chapters) {
+			c.End = chapters[n+1].Start
+		} else {
+			c.End = duration
+		}
+	}
+}
@@ -0,0 +1,95 @@
+package probe_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/podpalinc/rss-feed-generator/probe"
+	"github.com/stretchr/testify/assert"
+)
+
+// chapFrame returns an ID3v2.3 CHAP frame of the times in milliseconds and
+// embedded frames.
+func chapFrame(id string, start, end uint32, frames ...[]byte) []byte {
+	data := append([]byte(id), 0)
+	data = append(data, u32(start, end, 0xFFFFFFFF, 0xFFFFFFFF)...)
+	return id3v23Frame("CHAP", append(data, bytes.Join(frames, nil)...))
+}
+
+// ctocFrame returns an ID3v2.3 CTOC frame of the flags and children.
+func ctocFrame(id string, flags byte, children ...string) []byte {
+	data := append([]byte(id), 0, flags, byte(len(children)))
+	for _, c := range children {
+		data = append(data, c...)
+		data = append(data, 0)
+	}
+	return id3v23Frame("CTOC", data)
+}
+
+func TestReadMP3Chapters(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tag := id3v23(
+		ctocFrame("toc", 0x03, "chp1", "chp0"),
+		chapFrame("chp1", 10000, 20000,
+			id3v23Frame("TIT2", []byte("\x00Interview")),
+			id3v23Frame("WXXX", []byte("\x00\x00https://example.com/guest")),
+			id3v23Frame("APIC", []byte("\x00image/png\x00\x00\x00png")),
+		),
+		chapFrame("chp0", 0, 10000, id3v23Frame("TIT2", []byte("\x03Intro"))),
+		chapFrame("chp2", 20000, 0, id3v23Frame("TIT2", []byte("\x00Outro"))),
+	)
+	data := append(tag, mp3Frames(mp3Header, mp3FrameLen, 1000)...)
+
+	// act
+	info := readBytes(t, data)
+
+	// assert
+	if !assert.Len(t, info.Chapters, 3) {
+		return
+	}
+	intro, interview, outro := info.Chapters[0], info.Chapters[1], info.Chapters[2]
+	assert.Equal(t, probe.Chapter{Start: 0, End: 10 * time.Second, Title: "Intro"}, *intro)
+	assert.Equal(t, "Interview", interview.Title)
+	assert.Equal(t, 10*time.Second, interview.Start)
+	assert.Equal(t, 20*time.Second, interview.End)
+	assert.Equal(t, "https://example.com/guest", interview.URL)
+	if assert.NotNil(t, interview.Image) {
+		assert.Equal(t, "image/png", interview.Image.MIMEType)
+		assert.Equal(t, "png", string(interview.Image.Data))
+	}
+	assert.False(t, interview.Hidden)
+	assert.Equal(t, "Outro", outro.Title)
+	assert.Equal(t, info.Duration, outro.End)
+	assert.True(t, outro.Hidden)
+}
+
+func TestReadMP4Chapters(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	chpl := []byte{1, 0, 0, 0, 0, 0, 0, 0, 2}
+	for _, c := range []struct {
+		start uint64
+		title string
+	}{{0, "Intro"}, {305000000, "Interview"}} {
+		start := make([]byte, 8)
+		binary.BigEndian.PutUint64(start, c.start)
+		chpl = append(chpl, start...)
+		chpl = append(chpl, byte(len(c.title)))
+		chpl = append(chpl, c.title...)
+	}
+	data := mp4File("M4A ", mp4Track("soun"), box("udta", box("chpl", chpl)))
+
+	// act
+	info := readBytes(t, data)
+
+	// assert
+	assert.Equal(t, []*probe.Chapter{
+		{Start: 0, End: 30500 * time.Millisecond, Title: "Intro"},
+		{Start: 30500 * time.Millisecond, End: 60500 * time.Millisecond, Title: "Interview"},
+	}, info.Chapters)
+}
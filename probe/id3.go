@@ -0,0 +1,317 @@
+package probe
+
+import (
+	"encoding/binary"
+	"io"
+	"strings"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+)
+
+// id3HeaderLen is the length of the header, and of the footer, of an ID3v2
+// tag.
+const id3HeaderLen = 10
+
+// ID3v2 tag flags.
+const (
+	id3FlagUnsynchronisation = 0x80
+	id3FlagExtendedHeader    = 0x40
+	id3FlagFooter            = 0x10
+)
+
+// ID3v2 text encodings.
+const (
+	id3EncodingLatin1  = 0
+	id3EncodingUTF16   = 1
+	id3EncodingUTF16BE = 2
+	id3EncodingUTF8    = 3
+)
+
+// id3PictureFrontCover is the APIC picture type of the front cover.
+const id3PictureFrontCover = 3
+
+// id3Tag is an ID3v2 tag at the start of an MP3 file.
+type id3Tag struct {
+	// Version is the major version of the tag, 2, 3 or 4.
+	Version byte
+
+	// Size of the tag in bytes, header and footer included.
+	Size int64
+
+	Frames []id3Frame
+}
+
+// id3Frame is a frame of an ID3v2 tag, its data decoded from the
+// unsynchronisation scheme.
+type id3Frame struct {
+	ID   string
+	Data []byte
+}
+
+// readID3 reads the ID3v2 tag at the start of the media, or returns nil if
+// there is none.
+func readID3(r io.ReaderAt, size int64) (*id3Tag, error) {
+	header := make([]byte, id3HeaderLen)
+	if _, err := r.ReadAt(header, 0); err != nil {
+		if err == io.EOF {
+			return nil, nil
+		}
+		return nil, errors.Wrap(err, "probe.readID3: ReadAt returned error")
+	}
+	if string(header[:3]) != "ID3" {
+		return nil, nil
+	}
+
+	tag := &id3Tag{Version: header[3]}
+	flags := header[5]
+	bodyLen := int64(syncsafe(header[6:10]))
+	tag.Size = id3HeaderLen + bodyLen
+	if flags&id3FlagFooter != 0 {
+		tag.Size += id3HeaderLen
+	}
+	if tag.Size > size {
+		return nil, errors.New("probe: ID3 tag is larger than the file")
+	}
+	if tag.Version < 2 || tag.Version > 4 {
+		// unknown versions can still be skipped
+		return tag, nil
+	}
+
+	body := make([]byte, bodyLen)
+	if _, err := r.ReadAt(body, id3HeaderLen); err != nil {
+		return nil, errors.Wrap(err, "probe.readID3: ReadAt returned error")
+	}
+	if flags&id3FlagUnsynchronisation != 0 && tag.Version < 4 {
+		body = resync(body)
+	}
+	if flags&id3FlagExtendedHeader != 0 && tag.Version > 2 && len(body) >= 4 {
+		n := int(binary.BigEndian.Uint32(body)) + 4
+		if tag.Version == 4 {
+			n = int(syncsafe(body))
+		}
+		if n > len(body) {
+			return nil, errors.New("probe: ID3 extended header is larger than the tag")
+		}
+		body = body[n:]
+	}
+
+	tag.Frames = parseID3Frames(body, tag.Version)
+	return tag, nil
+}
+
+// parseID3Frames returns the frames of the ID3v2 tag body, or of the
+// embedded frames of a CHAP or CTOC frame, up to the padding.  Compressed and
+// encrypted frames are skipped.
+func parseID3Frames(b []byte, version byte) []id3Frame {
+	idLen, headerLen := 4, 10
+	if version == 2 {
+		idLen, headerLen = 3, 6
+	}
+
+	var frames []id3Frame
+	for len(b) >= headerLen && b[0] != 0 {
+		id := string(b[:idLen])
+		var n int
+		var format byte
+		switch version {
+		case 2:
+			n = int(b[3])<<16 | int(b[4])<<8 | int(b[5])
+		case 3:
+			n = int(binary.BigEndian.Uint32(b[4:8]))
+			format = b[9]
+		default:
+			n = int(syncsafe(b[4:8]))
+			format = b[9]
+		}
+		if n < 0 || headerLen+n > len(b) {
+			break
+		}
+		data := b[headerLen : headerLen+n]
+		b = b[headerLen+n:]
+
+		switch version {
+		case 3:
+			if format&0xC0 != 0 { // compressed or encrypted
+				continue
+			}
+			if format&0x20 != 0 && len(data) > 0 { // grouping identity
+				data = data[1:]
+			}
+		case 4:
+			if format&0x0C != 0 { // compressed or encrypted
+				continue
+			}
+			if format&0x40 != 0 && len(data) > 0 { // grouping identity
+				data = data[1:]
+			}
+			if format&0x01 != 0 && len(data) >= 4 { // data length indicator
+				data = data[4:]
+			}
+			if format&0x02 != 0 {
+				data = resync(data)
+			}
+		}
+		frames = append(frames, id3Frame{ID: id, Data: data})
+	}
+	return frames
+}
+
+// frame returns the first frame with one of the ids, or nil.
+func (t *id3Tag) frame(ids ...string) *id3Frame {
+	for n := range t.Frames {
+		for _, id := range ids {
+			if t.Frames[n].ID == id {
+				return &t.Frames[n]
+			}
+		}
+	}
+	return nil
+}
+
+// title returns the TIT2 title of the tag.
+func (t *id3Tag) title() string {
+	f := t.frame("TIT2", "TT2")
+	if f == nil {
+		return ""
+	}
+	return id3Text(f.Data)
+}
+
+// artwork returns the front cover of the tag, or its first picture.
+func (t *id3Tag) artwork() *Artwork {
+	var first *Artwork
+	for _, f := range t.Frames {
+		var a *Artwork
+		var pictureType byte
+		switch f.ID {
+		case "APIC":
+			a, pictureType = parseAPIC(f.Data)
+		case "PIC":
+			a, pictureType = parsePIC(f.Data)
+		}
+		if a == nil {
+			continue
+		}
+		if pictureType == id3PictureFrontCover {
+			return a
+		}
+		if first == nil {
+			first = a
+		}
+	}
+	return first
+}
+
+// parseAPIC reads the attached picture of an ID3v2.3 or 2.4 APIC frame.
+func parseAPIC(b []byte) (*Artwork, byte) {
+	if len(b) < 2 {
+		return nil, 0
+	}
+	enc := b[0]
+	mimeType, rest := splitTerminated(id3EncodingLatin1, b[1:])
+	if len(rest) < 1 {
+		return nil, 0
+	}
+	pictureType := rest[0]
+	_, data := splitTerminated(enc, rest[1:])
+	if len(data) == 0 {
+		return nil, 0
+	}
+	mt := strings.ToLower(decodeID3Text(id3EncodingLatin1, mimeType))
+	if len(mt) > 0 && !strings.Contains(mt, "/") {
+		mt = "image/" + mt
+	}
+	return &Artwork{MIMEType: mt, Data: data}, pictureType
+}
+
+// parsePIC reads the attached picture of an ID3v2.2 PIC frame.
+func parsePIC(b []byte) (*Artwork, byte) {
+	if len(b) < 5 {
+		return nil, 0
+	}
+	enc, format, pictureType := b[0], strings.ToLower(string(b[1:4])), b[4]
+	_, data := splitTerminated(enc, b[5:])
+	if len(data) == 0 {
+		return nil, 0
+	}
+	if format == "jpg" {
+		format = "jpeg"
+	}
+	return &Artwork{MIMEType: "image/" + format, Data: data}, pictureType
+}
+
+// id3Text returns the first string of a text information frame.
+func id3Text(b []byte) string {
+	if len(b) == 0 {
+		return ""
+	}
+	s, _ := splitTerminated(b[0], b[1:])
+	return decodeID3Text(b[0], s)
+}
+
+// splitTerminated splits the string in the encoding at its terminator, one
+// NUL byte or two for UTF-16, returning the string and the bytes after the
+// terminator.
+func splitTerminated(enc byte, b []byte) ([]byte, []byte) {
+	if enc == id3EncodingUTF16 || enc == id3EncodingUTF16BE {
+		for n := 0; n+1 < len(b); n += 2 {
+			if b[n] == 0 && b[n+1] == 0 {
+				return b[:n], b[n+2:]
+			}
+		}
+		return b, nil
+	}
+	for n, c := range b {
+		if c == 0 {
+			return b[:n], b[n+1:]
+		}
+	}
+	return b, nil
+}
+
+// decodeID3Text decodes the string in the ID3v2 text encoding.
+func decodeID3Text(enc byte, b []byte) string {
+	switch enc {
+	case id3EncodingUTF16, id3EncodingUTF16BE:
+		order := binary.ByteOrder(binary.BigEndian)
+		if enc == id3EncodingUTF16 && len(b) >= 2 {
+			switch {
+			case b[0] == 0xFF && b[1] == 0xFE:
+				order, b = binary.LittleEndian, b[2:]
+			case b[0] == 0xFE && b[1] == 0xFF:
+				b = b[2:]
+			}
+		}
+		u := make([]uint16, len(b)/2)
+		for n := range u {
+			u[n] = order.Uint16(b[2*n:])
+		}
+		return strings.TrimRight(string(utf16.Decode(u)), "\x00")
+	case id3EncodingUTF8:
+		return strings.TrimRight(string(b), "\x00")
+	}
+	r := make([]rune, len(b))
+	for n, c := range b {
+		r[n] = rune(c)
+	}
+	return strings.TrimRight(string(r), "\x00")
+}
+
+// syncsafe decodes the 28 bit integer stored in the 7 low bits of 4 bytes.
+func syncsafe(b []byte) uint32 {
+	return uint32(b[0]&0x7F)<<21 | uint32(b[1]&0x7F)<<14 | uint32(b[2]&0x7F)<<7 | uint32(b[3]&0x7F)
+}
+
+// resync reverts the unsynchronisation scheme, dropping the 0x00 inserted
+// after each 0xFF.
+func resync(b []byte) []byte {
+	out := make([]byte, 0, len(b))
+	for n := 0; n < len(b); n++ {
+		out = append(out, b[n])
+		if b[n] == 0xFF && n+1 < len(b) && b[n+1] == 0 {
+			n++
+		}
+	}
+	return out
+}
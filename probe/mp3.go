@@ -0,0 +1,298 @@
+package probe
+
+import (
+	"bufio"
+	"encoding/binary"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// MPEG audio versions.
+const (
+	mpeg1  = 1
+	mpeg2  = 2
+	mpeg25 = 25
+)
+
+// syncSearchLen is how far past the ID3 tag the first frame is looked for.
+const syncSearchLen = 64 << 10
+
+// mpegBitrates are the bitrates in kbps by MPEG-1 layer, then MPEG-2 and 2.5
+// layer, indexed by the bitrate index of the frame header.
+var mpegBitrates = [2][4][16]int{
+	{
+		{},
+		{0, 32, 64, 96, 128, 160, 192, 224, 256, 288, 320, 352, 384, 416, 448},
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320, 384},
+		{0, 32, 40, 48, 56, 64, 80, 96, 112, 128, 160, 192, 224, 256, 320},
+	},
+	{
+		{},
+		{0, 32, 48, 56, 64, 80, 96, 112, 128, 144, 160, 176, 192, 224, 256},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+		{0, 8, 16, 24, 32, 40, 48, 56, 64, 80, 96, 112, 128, 144, 160},
+	},
+}
+
+// mpegSampleRates are the sample rates in Hz by version, indexed by the
+// sample rate index of the frame header.
+var mpegSampleRates = map[int][3]int{
+	mpeg1:  {44100, 48000, 32000},
+	mpeg2:  {22050, 24000, 16000},
+	mpeg25: {11025, 12000, 8000},
+}
+
+// mpegFrame is the header of an MPEG audio frame.
+type mpegFrame struct {
+	version    int
+	layer      int
+	bitrate    int
+	sampleRate int
+	padding    int
+	channels   int
+}
+
+// isFrameSync reports whether the bytes start with the 11 sync bits of an
+// MPEG audio frame.
+func isFrameSync(b0, b1 byte) bool {
+	return b0 == 0xFF && b1&0xE0 == 0xE0
+}
+
+// parseFrameHeader parses the 4 byte header of an MPEG audio frame,
+// reporting whether it is valid.
+func parseFrameHeader(b []byte) (mpegFrame, bool) {
+	if len(b) < 4 || !isFrameSync(b[0], b[1]) {
+		return mpegFrame{}, false
+	}
+
+	var f mpegFrame
+	switch (b[1] >> 3) & 0x03 {
+	case 0:
+		f.version = mpeg25
+	case 2:
+		f.version = mpeg2
+	case 3:
+		f.version = mpeg1
+	default:
+		return mpegFrame{}, false
+	}
+	f.layer = 4 - int((b[1]>>1)&0x03)
+	bitrateIndex, sampleRateIndex := b[2]>>4, (b[2]>>2)&0x03
+	if f.layer == 4 || bitrateIndex == 0 || bitrateIndex == 15 || sampleRateIndex == 3 {
+		return mpegFrame{}, false
+	}
+
+	table := 0
+	if f.version != mpeg1 {
+		table = 1
+	}
+	f.bitrate = mpegBitrates[table][f.layer][bitrateIndex] * 1000
+	f.sampleRate = mpegSampleRates[f.version][sampleRateIndex]
+	f.padding = int((b[2] >> 1) & 0x01)
+	f.channels = 2
+	if b[3]>>6 == 3 {
+		f.channels = 1
+	}
+	return f, true
+}
+
+// size returns the length of the frame in bytes, header included.
+func (f mpegFrame) size() int {
+	switch {
+	case f.layer == 1:
+		return (12*f.bitrate/f.sampleRate + f.padding) * 4
+	case f.layer == 3 && f.version != mpeg1:
+		return 72*f.bitrate/f.sampleRate + f.padding
+	}
+	return 144*f.bitrate/f.sampleRate + f.padding
+}
+
+// samples returns the number of samples per channel of the frame.
+func (f mpegFrame) samples() int {
+	switch {
+	case f.layer == 1:
+		return 384
+	case f.layer == 3 && f.version != mpeg1:
+		return 576
+	}
+	return 1152
+}
+
+// sideInfoLen returns the length of the layer III side information following
+// the header, where the Xing header starts.
+func (f mpegFrame) sideInfoLen() int {
+	switch {
+	case f.version == mpeg1 && f.channels == 1:
+		return 17
+	case f.version == mpeg1:
+		return 32
+	case f.channels == 1:
+		return 9
+	}
+	return 17
+}
+
+// sameStream reports whether the frames belong to the same stream, to tell
+// frames from bytes that happen to look like a frame header.
+func (f mpegFrame) sameStream(g mpegFrame) bool {
+	return f.version == g.version && f.layer == g.layer && f.sampleRate == g.sampleRate
+}
+
+// duration returns the duration of the samples per channel.
+func (f mpegFrame) duration(samples int64) time.Duration {
+	return time.Duration(samples) * time.Second / time.Duration(f.sampleRate)
+}
+
+func readMP3(r io.ReaderAt, size int64) (*Info, error) {
+	info := &Info{Format: FormatMP3, MIMEType: "audio/mpeg", Length: size}
+
+	tag, err := readID3(r, size)
+	if err != nil {
+		return nil, err
+	}
+	var start int64
+	if tag != nil {
+		start = tag.Size
+		info.Title = tag.title()
+		info.Artwork = tag.artwork()
+		info.Chapters = tag.chapters()
+	}
+
+	end := size
+	if size-start >= 128 {
+		trailer := make([]byte, 3)
+		if _, err := r.ReadAt(trailer, size-128); err != nil {
+			return nil, errors.Wrap(err, "probe.readMP3: ReadAt returned error")
+		}
+		if string(trailer) == "TAG" { // ID3v1
+			end -= 128
+		}
+	}
+
+	pos, first, err := findFirstFrame(r, start, end)
+	if err != nil {
+		return nil, err
+	}
+	info.SampleRate = first.sampleRate
+	info.Channels = first.channels
+
+	frames, bytes, err := readVBRHeader(r, pos, first)
+	if err != nil {
+		return nil, err
+	}
+	if frames > 0 {
+		if bytes == 0 {
+			bytes = end - pos
+		}
+		info.Duration = first.duration(frames * int64(first.samples()))
+		info.Bitrate = bitrate(bytes, info.Duration)
+		return info, nil
+	}
+
+	samples, bytes, err := scanFrames(r, pos, end, first)
+	if err != nil {
+		return nil, err
+	}
+	info.Duration = first.duration(samples)
+	info.Bitrate = bitrate(bytes, info.Duration)
+	return info, nil
+}
+
+// findFirstFrame returns the offset and header of the first frame from the
+// start, confirmed by the header of the frame following it.
+func findFirstFrame(r io.ReaderAt, start, end int64) (int64, mpegFrame, error) {
+	if start >= end {
+		return 0, mpegFrame{}, errors.New("probe: no MPEG audio frame found")
+	}
+	n := end - start
+	if n > syncSearchLen {
+		n = syncSearchLen
+	}
+	b := make([]byte, n)
+	if _, err := r.ReadAt(b, start); err != nil && err != io.EOF {
+		return 0, mpegFrame{}, errors.Wrap(err, "probe.findFirstFrame: ReadAt returned error")
+	}
+
+	for i := 0; i+4 <= len(b); i++ {
+		f, ok := parseFrameHeader(b[i:])
+		if !ok {
+			continue
+		}
+		next := int64(i + f.size())
+		if start+next >= end {
+			return start + int64(i), f, nil
+		}
+		h := make([]byte, 4)
+		if _, err := r.ReadAt(h, start+next); err != nil && err != io.EOF {
+			return 0, mpegFrame{}, errors.Wrap(err, "probe.findFirstFrame: ReadAt returned error")
+		}
+		if g, ok := parseFrameHeader(h); ok && f.sameStream(g) {
+			return start + int64(i), f, nil
+		}
+	}
+	return 0, mpegFrame{}, errors.New("probe: no MPEG audio frame found")
+}
+
+// readVBRHeader returns the number of frames and of bytes of the audio from
+// the Xing, Info or VBRI header of the first frame, or 0 frames if it has
+// none.
+func readVBRHeader(r io.ReaderAt, pos int64, f mpegFrame) (int64, int64, error) {
+	b := make([]byte, f.size())
+	n, err := r.ReadAt(b, pos)
+	if err != nil && err != io.EOF {
+		return 0, 0, errors.Wrap(err, "probe.readVBRHeader: ReadAt returned error")
+	}
+	b = b[:n]
+
+	if x := 4 + f.sideInfoLen(); len(b) >= x+8 {
+		if id := string(b[x : x+4]); id == "Xing" || id == "Info" {
+			flags := binary.BigEndian.Uint32(b[x+4:])
+			x += 8
+			var frames, bytes int64
+			if flags&0x01 != 0 && len(b) >= x+4 {
+				frames = int64(binary.BigEndian.Uint32(b[x:]))
+				x += 4
+			}
+			if flags&0x02 != 0 && len(b) >= x+4 {
+				bytes = int64(binary.BigEndian.Uint32(b[x:]))
+			}
+			return frames, bytes, nil
+		}
+	}
+
+	if x := 4 + 32; len(b) >= x+18 && string(b[x:x+4]) == "VBRI" {
+		bytes := int64(binary.BigEndian.Uint32(b[x+10:]))
+		frames := int64(binary.BigEndian.Uint32(b[x+14:]))
+		return frames, bytes, nil
+	}
+	return 0, 0, nil
+}
+
+// scanFrames walks the frames of the stream from the first one up to the
+// end, returning the number of samples and of bytes of the audio.  Bytes
+// between frames are skipped.
+func scanFrames(r io.ReaderAt, pos, end int64, first mpegFrame) (int64, int64, error) {
+	br := bufio.NewReaderSize(io.NewSectionReader(r, pos, end-pos), 64<<10)
+	remaining := end - pos
+
+	var samples, bytes int64
+	for remaining >= 4 {
+		h, err := br.Peek(4)
+		if err != nil {
+			return 0, 0, errors.Wrap(err, "probe.scanFrames: Peek returned error")
+		}
+		skip := int64(1)
+		if f, ok := parseFrameHeader(h); ok && first.sameStream(f) && int64(f.size()) <= remaining {
+			skip = int64(f.size())
+			samples += int64(f.samples())
+			bytes += skip
+		}
+		if _, err := br.Discard(int(skip)); err != nil {
+			return 0, 0, errors.Wrap(err, "probe.scanFrames: Discard returned error")
+		}
+		remaining -= skip
+	}
+	return samples, bytes, nil
+}
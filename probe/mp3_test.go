@@ -0,0 +1,186 @@
+package probe_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"testing"
+	"time"
+
+	"github.com/podpalinc/rss-feed-generator/probe"
+	"github.com/stretchr/testify/assert"
+)
+
+func readBytes(t *testing.T, data []byte) *probe.Info {
+	info, err := probe.Read(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return info
+}
+
+func TestReadMP3(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	back := append([]byte("\x00image/png\x00\x04\x00"), "back"...)
+	front := append([]byte("\x01image/jpeg\x00\x03"), utf16Text("cover")...)
+	front = append(front, 0, 0)
+	front = append(front, "front"...)
+	tag := id3v23(
+		id3v23Frame("TIT2", append([]byte{1}, utf16Text("Épisode 1")...)),
+		id3v23Frame("APIC", back),
+		id3v23Frame("APIC", front),
+	)
+	v1 := make([]byte, 128)
+	copy(v1, "TAG")
+	data := bytes.Join([][]byte{tag, mp3Frames(mp3Header, mp3FrameLen, 1000), v1}, nil)
+
+	// act
+	info := readBytes(t, data)
+
+	// assert
+	assert.Equal(t, "audio/mpeg", info.MIMEType)
+	assert.EqualValues(t, len(data), info.Length)
+	assert.Equal(t, 26122448*time.Microsecond, info.Duration.Truncate(time.Microsecond))
+	assert.InDelta(t, 128000, info.Bitrate, 500)
+	assert.Equal(t, 44100, info.SampleRate)
+	assert.Equal(t, 2, info.Channels)
+	assert.Equal(t, "Épisode 1", info.Title)
+	if assert.NotNil(t, info.Artwork) {
+		assert.Equal(t, "image/jpeg", info.Artwork.MIMEType)
+		assert.Equal(t, "front", string(info.Artwork.Data))
+	}
+}
+
+func TestReadMP3Xing(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	data := append(xingFrame("Xing", 1000, 1000*mp3FrameLen), mp3Frames(mp3Header, mp3FrameLen, 10)...)
+
+	// act
+	info := readBytes(t, data)
+
+	// assert
+	assert.Equal(t, 26122448*time.Microsecond, info.Duration.Truncate(time.Microsecond))
+	assert.InDelta(t, 128000, info.Bitrate, 500)
+}
+
+func TestReadMP3VBRI(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	frame := make([]byte, mp3FrameLen)
+	copy(frame, mp3Header)
+	copy(frame[36:], "VBRI")
+	binary.BigEndian.PutUint32(frame[46:], 500*mp3FrameLen)
+	binary.BigEndian.PutUint32(frame[50:], 500)
+	data := append(frame, mp3Frames(mp3Header, mp3FrameLen, 10)...)
+
+	// act
+	info := readBytes(t, data)
+
+	// assert
+	assert.Equal(t, 13061224*time.Microsecond, info.Duration.Truncate(time.Microsecond))
+}
+
+func TestReadMP3Resync(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	// a tag with a frame header of another stream, and junk between frames
+	tag := id3v23(id3v23Frame("TXXX", []byte{0, 0xFF, 0xF3, 0x80, 0xC0}))
+	data := bytes.Join([][]byte{
+		tag,
+		[]byte("junk\xFF\xFB"),
+		mp3Frames(mp3Header, mp3FrameLen, 50),
+		[]byte("junk"),
+		mp3Frames(mp3Header, mp3FrameLen, 50),
+	}, nil)
+
+	// act
+	info := readBytes(t, data)
+
+	// assert
+	assert.Equal(t, 2612244*time.Microsecond, info.Duration.Truncate(time.Microsecond))
+}
+
+func TestReadMP3MPEG2Mono(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	// MPEG-2 layer III of 64 kbps at 22.05 kHz in mono, of 576 samples
+	header := []byte{0xFF, 0xF3, 0x80, 0xC0}
+	data := mp3Frames(header, 208, 100)
+
+	// act
+	info := readBytes(t, data)
+
+	// assert
+	assert.Equal(t, 22050, info.SampleRate)
+	assert.Equal(t, 1, info.Channels)
+	assert.Equal(t, 2612244*time.Microsecond, info.Duration.Truncate(time.Microsecond))
+}
+
+func TestReadMP3ID3v22(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	frame := func(id string, data []byte) []byte {
+		n := len(data)
+		return append([]byte{id[0], id[1], id[2], byte(n >> 16), byte(n >> 8), byte(n)}, data...)
+	}
+	body := append(frame("TT2", []byte("\x00Caf\xe9\x00")), frame("PIC", []byte("\x00JPG\x03\x00jpeg"))...)
+	n := len(body)
+	tag := append([]byte{'I', 'D', '3', 2, 0, 0, 0, 0, byte(n >> 7), byte(n & 0x7F)}, body...)
+	data := append(tag, mp3Frames(mp3Header, mp3FrameLen, 10)...)
+
+	// act
+	info := readBytes(t, data)
+
+	// assert
+	assert.Equal(t, "Café", info.Title)
+	if assert.NotNil(t, info.Artwork) {
+		assert.Equal(t, "image/jpeg", info.Artwork.MIMEType)
+		assert.Equal(t, "jpeg", string(info.Artwork.Data))
+	}
+}
+
+func TestReadMP3NoFrames(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	data := id3v23(id3v23Frame("TIT2", []byte("\x00title")))
+
+	// act
+	_, err := probe.Read(bytes.NewReader(data), int64(len(data)))
+
+	// assert
+	assert.EqualError(t, err, "probe: no MPEG audio frame found")
+}
+
+func TestReadMP3InvalidID3Size(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	tests := []struct {
+		name   string
+		header string
+	}{
+		{name: "footer", header: "ID3\x04\x00\x10\x00\x00\x00\x00"},
+		{name: "unknown version", header: "ID3\x05\x00\x00\x00\x00\x7F\x7F"},
+	}
+
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			_, err := probe.Read(bytes.NewReader([]byte(tt.header)), int64(len(tt.header)))
+
+			// assert
+			assert.EqualError(t, err, "probe: ID3 tag is larger than the file")
+		})
+	}
+}
@@ -0,0 +1,348 @@
+package probe
+
+import (
+	"encoding/binary"
+	"io"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// maxMP4BoxRead is the largest box read into memory, to guard against
+// corrupt sizes.  Media data is never read.
+const maxMP4BoxRead = 64 << 20
+
+// iTunes metadata data types.
+const (
+	mp4DataJPEG = 13
+	mp4DataPNG  = 14
+)
+
+// mp4Box is an atom of an MP4 file.
+type mp4Box struct {
+	Type string
+
+	// Offset of the payload of the box in the file, after its header.
+	Offset int64
+
+	// Size of the payload in bytes.
+	Size int64
+}
+
+// readMP4Boxes returns the boxes between the offsets.
+func readMP4Boxes(r io.ReaderAt, start, end int64) ([]mp4Box, error) {
+	var boxes []mp4Box
+	header := make([]byte, 16)
+	for start+8 <= end {
+		if _, err := r.ReadAt(header[:8], start); err != nil {
+			return nil, errors.Wrap(err, "probe.readMP4Boxes: ReadAt returned error")
+		}
+		size, headerLen := int64(binary.BigEndian.Uint32(header)), int64(8)
+		switch size {
+		case 0: // up to the end of the file
+			size = end - start
+		case 1: // 64 bit size
+			if _, err := r.ReadAt(header[8:16], start+8); err != nil {
+				return nil, errors.Wrap(err, "probe.readMP4Boxes: ReadAt returned error")
+			}
+			size, headerLen = int64(binary.BigEndian.Uint64(header[8:])), 16
+		}
+		if size < headerLen || start+size > end {
+			return nil, errors.New("probe: invalid MP4 box " + string(header[4:8]))
+		}
+		boxes = append(boxes, mp4Box{
+			Type:   string(header[4:8]),
+			Offset: start + headerLen,
+			Size:   size - headerLen,
+		})
+		start += size
+	}
+	return boxes, nil
+}
+
+// children returns the boxes in the payload of the box, after skip bytes.
+func (b mp4Box) children(r io.ReaderAt, skip int64) ([]mp4Box, error) {
+	return readMP4Boxes(r, b.Offset+skip, b.Offset+b.Size)
+}
+
+// read returns the payload of the box.
+func (b mp4Box) read(r io.ReaderAt) ([]byte, error) {
+	if b.Size > maxMP4BoxRead {
+		return nil, errors.New("probe: MP4 box " + b.Type + " is too large")
+	}
+	p := make([]byte, b.Size)
+	if _, err := r.ReadAt(p, b.Offset); err != nil {
+		return nil, errors.Wrap(err, "probe.mp4Box.read: ReadAt returned error")
+	}
+	return p, nil
+}
+
+// findMP4Box returns the first box of the type, or nil.
+func findMP4Box(boxes []mp4Box, boxType string) *mp4Box {
+	for n := range boxes {
+		if boxes[n].Type == boxType {
+			return &boxes[n]
+		}
+	}
+	return nil
+}
+
+// findMP4Path returns the box at the path of types down from the boxes, or
+// nil.
+func findMP4Path(r io.ReaderAt, boxes []mp4Box, path ...string) (*mp4Box, error) {
+	for n, t := range path {
+		b := findMP4Box(boxes, t)
+		if b == nil || n == len(path)-1 {
+			return b, nil
+		}
+		var err error
+		if boxes, err = b.children(r, 0); err != nil {
+			return nil, err
+		}
+	}
+	return nil, nil
+}
+
+// mp4Reader accumulates the Info of an MP4 file from its boxes.
+type mp4Reader struct {
+	r        io.ReaderAt
+	info     *Info
+	brand    string
+	hasVideo bool
+}
+
+func readMP4(r io.ReaderAt, size int64) (*Info, error) {
+	m := &mp4Reader{r: r, info: &Info{Format: FormatMP4, Length: size}}
+
+	boxes, err := readMP4Boxes(r, 0, size)
+	if err != nil {
+		return nil, err
+	}
+	var mediaBytes int64
+	for _, b := range boxes {
+		switch b.Type {
+		case "ftyp":
+			p, err := b.read(r)
+			if err != nil {
+				return nil, err
+			}
+			if len(p) >= 4 {
+				m.brand = string(p[:4])
+			}
+		case "moov":
+			if err := m.moov(b); err != nil {
+				return nil, err
+			}
+		case "mdat":
+			mediaBytes += b.Size
+		}
+	}
+	if mediaBytes == 0 {
+		mediaBytes = size
+	}
+
+	m.info.MIMEType = m.mimeType()
+	m.info.Bitrate = bitrate(mediaBytes, m.info.Duration)
+	return m.info, nil
+}
+
+// mimeType returns the MIME type of the brand, or of the tracks of generic
+// brands.
+func (m *mp4Reader) mimeType() string {
+	switch m.brand {
+	case "M4A ", "M4B ", "M4P ":
+		return "audio/x-m4a"
+	case "M4V ", "M4VH", "M4VP":
+		return "video/x-m4v"
+	case "qt  ":
+		return "video/quicktime"
+	}
+	if m.hasVideo {
+		return "video/mp4"
+	}
+	return "audio/mp4"
+}
+
+func (m *mp4Reader) moov(moov mp4Box) error {
+	boxes, err := moov.children(m.r, 0)
+	if err != nil {
+		return err
+	}
+	for _, b := range boxes {
+		switch b.Type {
+		case "mvhd":
+			if err := m.mvhd(b); err != nil {
+				return err
+			}
+		case "trak":
+			if err := m.trak(b); err != nil {
+				return err
+			}
+		case "udta":
+			udta, err := b.children(m.r, 0)
+			if err != nil {
+				return err
+			}
+			if meta := findMP4Box(udta, "meta"); meta != nil {
+				if err := m.meta(*meta); err != nil {
+					return err
+				}
+			}
+			if chpl := findMP4Box(udta, "chpl"); chpl != nil {
+				p, err := chpl.read(m.r)
+				if err != nil {
+					return err
+				}
+				m.info.Chapters = parseCHPL(p)
+			}
+		case "meta":
+			if err := m.meta(b); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// mvhd reads the duration of the movie header.
+func (m *mp4Reader) mvhd(b mp4Box) error {
+	p, err := b.read(m.r)
+	if err != nil {
+		return err
+	}
+	var timescale, duration uint64
+	switch {
+	case len(p) >= 32 && p[0] == 1:
+		timescale = uint64(binary.BigEndian.Uint32(p[20:]))
+		duration = binary.BigEndian.Uint64(p[24:])
+	case len(p) >= 20:
+		timescale = uint64(binary.BigEndian.Uint32(p[12:]))
+		duration = uint64(binary.BigEndian.Uint32(p[16:]))
+	default:
+		return errors.New("probe: MP4 mvhd box is too short")
+	}
+	if timescale > 0 {
+		m.info.Duration = time.Duration(float64(duration) / float64(timescale) * float64(time.Second))
+	}
+	return nil
+}
+
+// trak reads the handler of the track, and the sample rate and channels of
+// the first audio track.
+func (m *mp4Reader) trak(trak mp4Box) error {
+	boxes, err := trak.children(m.r, 0)
+	if err != nil {
+		return err
+	}
+	mdia := findMP4Box(boxes, "mdia")
+	if mdia == nil {
+		return nil
+	}
+	boxes, err = mdia.children(m.r, 0)
+	if err != nil {
+		return err
+	}
+	hdlr := findMP4Box(boxes, "hdlr")
+	if hdlr == nil {
+		return nil
+	}
+	p, err := hdlr.read(m.r)
+	if err != nil {
+		return err
+	}
+	if len(p) < 12 {
+		return nil
+	}
+	switch string(p[8:12]) {
+	case "vide":
+		m.hasVideo = true
+	case "soun":
+		if m.info.SampleRate == 0 {
+			return m.sound(boxes)
+		}
+	}
+	return nil
+}
+
+// sound reads the sample entry of the audio track from the boxes of its
+// mdia box.
+func (m *mp4Reader) sound(mdia []mp4Box) error {
+	stsd, err := findMP4Path(m.r, mdia, "minf", "stbl", "stsd")
+	if err != nil || stsd == nil {
+		return err
+	}
+
+	p, err := stsd.read(m.r)
+	if err != nil {
+		return err
+	}
+	// full box header and entry count, then the audio sample entry
+	const entry = 8
+	if len(p) < entry+36 {
+		return nil
+	}
+	m.info.Channels = int(binary.BigEndian.Uint16(p[entry+24:]))
+	m.info.SampleRate = int(binary.BigEndian.Uint16(p[entry+32:]))
+	return nil
+}
+
+// meta reads the title and cover art of the iTunes metadata.
+func (m *mp4Reader) meta(meta mp4Box) error {
+	// meta is a full box, except in QuickTime files
+	skip := int64(4)
+	if meta.Size >= 8 {
+		head := make([]byte, 8)
+		if _, err := m.r.ReadAt(head, meta.Offset); err != nil {
+			return errors.Wrap(err, "probe.mp4Reader.meta: ReadAt returned error")
+		}
+		if string(head[4:8]) == "hdlr" {
+			skip = 0
+		}
+	}
+	boxes, err := meta.children(m.r, skip)
+	if err != nil {
+		return err
+	}
+	ilst := findMP4Box(boxes, "ilst")
+	if ilst == nil {
+		return nil
+	}
+	items, err := ilst.children(m.r, 0)
+	if err != nil {
+		return err
+	}
+
+	for _, item := range items {
+		if item.Type != "\xa9nam" && item.Type != "covr" {
+			continue
+		}
+		boxes, err := item.children(m.r, 0)
+		if err != nil {
+			return err
+		}
+		data := findMP4Box(boxes, "data")
+		if data == nil {
+			continue
+		}
+		p, err := data.read(m.r)
+		if err != nil {
+			return err
+		}
+		if len(p) < 8 {
+			continue
+		}
+		dataType, value := binary.BigEndian.Uint32(p)&0xFFFFFF, p[8:]
+
+		if item.Type == "\xa9nam" {
+			m.info.Title = string(value)
+			continue
+		}
+		switch dataType {
+		case mp4DataJPEG:
+			m.info.Artwork = &Artwork{MIMEType: "image/jpeg", Data: value}
+		case mp4DataPNG:
+			m.info.Artwork = &Artwork{MIMEType: "image/png", Data: value}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,110 @@
+package probe_test
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/podpalinc/rss-feed-generator/probe"
+	"github.com/stretchr/testify/assert"
+)
+
+// mp4Track returns a trak box of the handler type, with an mp4a sample entry
+// for sound.
+func mp4Track(handler string) []byte {
+	hdlr := box("hdlr", u32(0, 0), []byte(handler), make([]byte, 13))
+	if handler != "soun" {
+		return box("trak", box("mdia", hdlr))
+	}
+	entry := box("mp4a",
+		make([]byte, 6), []byte{0, 1}, // reserved, data reference index
+		make([]byte, 8),     // version, revision and vendor
+		[]byte{0, 2, 0, 16}, // channels, sample size
+		make([]byte, 4),     // compression id, packet size
+		u32(44100<<16),      // sample rate
+	)
+	stsd := box("stsd", u32(0, 1), entry)
+	return box("trak", box("mdia", hdlr, box("minf", box("stbl", stsd))))
+}
+
+// mp4File returns an MP4 file of the brand, 60.5 seconds long, with the
+// iTunes metadata and the boxes, such as tracks, in its moov box.
+func mp4File(brand string, boxes ...[]byte) []byte {
+	mvhd := box("mvhd", u32(0, 0, 0, 1000, 60500), make([]byte, 80))
+	ilst := box("ilst",
+		box("\xa9nam", box("data", u32(1, 0), []byte("Episode 1"))),
+		box("covr", box("data", u32(14, 0), []byte("png"))),
+	)
+	udta := box("udta", box("meta", u32(0), box("hdlr", u32(0, 0), []byte("mdir"), make([]byte, 13)), ilst))
+	moov := box("moov", mvhd, bytes.Join(boxes, nil), udta)
+	return bytes.Join([][]byte{
+		box("ftyp", []byte(brand), u32(0), []byte("isommp42")),
+		moov,
+		box("mdat", make([]byte, 968000/8*60)),
+	}, nil)
+}
+
+func TestReadMP4(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	data := mp4File("M4A ", mp4Track("soun"))
+
+	// act
+	info := readBytes(t, data)
+
+	// assert
+	assert.Equal(t, probe.FormatMP4, info.Format)
+	assert.Equal(t, "audio/x-m4a", info.MIMEType)
+	assert.EqualValues(t, len(data), info.Length)
+	assert.Equal(t, 60500*time.Millisecond, info.Duration)
+	assert.InDelta(t, 960000, info.Bitrate, 1000)
+	assert.Equal(t, 44100, info.SampleRate)
+	assert.Equal(t, 2, info.Channels)
+	assert.Equal(t, "Episode 1", info.Title)
+	if assert.NotNil(t, info.Artwork) {
+		assert.Equal(t, "image/png", info.Artwork.MIMEType)
+		assert.Equal(t, "png", string(info.Artwork.Data))
+	}
+}
+
+func TestReadMP4MIMEType(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		name   string
+		data   []byte
+		expect string
+	}{
+		{"m4v", mp4File("M4V ", mp4Track("soun"), mp4Track("vide")), "video/x-m4v"},
+		{"quicktime", mp4File("qt  ", mp4Track("vide")), "video/quicktime"},
+		{"video", mp4File("isom", mp4Track("vide"), mp4Track("soun")), "video/mp4"},
+		{"audio", mp4File("isom", mp4Track("soun")), "audio/mp4"},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// act
+			info := readBytes(t, tt.data)
+
+			// assert
+			assert.Equal(t, tt.expect, info.MIMEType)
+		})
+	}
+}
+
+func TestReadMP4InvalidBox(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	data := append(box("ftyp", []byte("M4A "), u32(0)), u32(1000)...)
+	data = append(data, "moov"...)
+
+	// act
+	_, err := probe.Read(bytes.NewReader(data), int64(len(data)))
+
+	// assert
+	assert.EqualError(t, err, "probe: invalid MP4 box moov")
+}
@@ -0,0 +1,123 @@
+// Package probe reads the length, duration and audio properties of podcast
+// media files, along with their embedded title, artwork and chapters,
+// without calling out to ffprobe.
+//
+// It understands MP3 files, with their ID3v2 tag and Xing, Info or VBRI
+// header, counting the frames of those without one, and MP4 files such as
+// M4A and M4V, reading their moov atoms.
+package probe
+
+import (
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// Formats of the media Read understands.
+const (
+	FormatMP3 = "mp3"
+	FormatMP4 = "mp4"
+)
+
+// ErrUnsupportedFormat is returned for media other than MP3 and MP4.
+var ErrUnsupportedFormat = errors.New("probe: unsupported media format")
+
+// Info describes a media file.
+type Info struct {
+	// Format of the media, FormatMP3 or FormatMP4.
+	Format string
+
+	// MIMEType of the media, such as audio/mpeg, audio/x-m4a or video/mp4.
+	MIMEType string
+
+	// Length of the file in bytes.
+	Length int64
+
+	// Duration of the media.
+	Duration time.Duration
+
+	// Bitrate of the audio in bits per second, averaged over the media for
+	// variable bitrates.
+	Bitrate int
+
+	// SampleRate of the audio in Hz.
+	SampleRate int
+
+	// Channels of the audio, 1 for mono and 2 for stereo.
+	Channels int
+
+	// Title embedded in the ID3 tag or the MP4 metadata.
+	Title string
+
+	// Artwork embedded in the ID3 tag or the MP4 metadata, the front cover
+	// when there are several pictures.
+	Artwork *Artwork
+
+	// Chapters embedded in the ID3 tag or the MP4 metadata, sorted by start
+	// time.  Those without an end time end where the next one starts.
+	Chapters []*Chapter
+}
+
+// Artwork is an image embedded in a media file.
+type Artwork struct {
+	// MIMEType of the image, such as image/jpeg or image/png.
+	MIMEType string
+
+	Data []byte
+}
+
+// File reads the Info of the media file.
+func File(name string) (*Info, error) {
+	f, err := os.Open(name)
+	if err != nil {
+		return nil, errors.Wrap(err, "probe.File: os.Open returned error")
+	}
+	defer f.Close()
+
+	fi, err := f.Stat()
+	if err != nil {
+		return nil, errors.Wrap(err, "probe.File: Stat returned error")
+	}
+	info, err := Read(f, fi.Size())
+	if err != nil {
+		return nil, errors.Wrap(err, name)
+	}
+	return info, nil
+}
+
+// Read reads the Info of the media of size bytes, detecting its format from
+// its first bytes.
+func Read(r io.ReaderAt, size int64) (*Info, error) {
+	head := make([]byte, 12)
+	n, err := r.ReadAt(head, 0)
+	if err != nil && err != io.EOF {
+		return nil, errors.Wrap(err, "probe.Read: ReadAt returned error")
+	}
+	head = head[:n]
+
+	var info *Info
+	switch {
+	case n >= 8 && string(head[4:8]) == "ftyp":
+		info, err = readMP4(r, size)
+	case n >= 3 && string(head[:3]) == "ID3",
+		n >= 2 && isFrameSync(head[0], head[1]):
+		info, err = readMP3(r, size)
+	default:
+		return nil, ErrUnsupportedFormat
+	}
+	if err != nil {
+		return nil, err
+	}
+	endChapters(info.Chapters, info.Duration)
+	return info, nil
+}
+
+// bitrate returns the average bitrate of the bytes played over the duration.
+func bitrate(bytes int64, d time.Duration) int {
+	if d <= 0 {
+		return 0
+	}
+	return int(float64(bytes) * 8 / d.Seconds())
+}
@@ -0,0 +1,146 @@
+package probe_test
+
+import (
+	"bytes"
+	"encoding/binary"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/pkg/errors"
+	"github.com/podpalinc/rss-feed-generator/probe"
+	"github.com/stretchr/testify/assert"
+)
+
+// mp3Header is an MPEG-1 layer III frame header of 128 kbps at 44.1 kHz in
+// stereo, the frames of which are 417 bytes and 1152 samples long.
+var mp3Header = []byte{0xFF, 0xFB, 0x90, 0x00}
+
+const mp3FrameLen = 417
+
+// mp3Frames returns n silent frames of the header.
+func mp3Frames(header []byte, size, n int) []byte {
+	var b bytes.Buffer
+	for i := 0; i < n; i++ {
+		frame := make([]byte, size)
+		copy(frame, header)
+		b.Write(frame)
+	}
+	return b.Bytes()
+}
+
+// xingFrame returns a frame of the header holding a Xing header.
+func xingFrame(id string, frames, bytes uint32) []byte {
+	frame := make([]byte, mp3FrameLen)
+	copy(frame, mp3Header)
+	copy(frame[36:], id)
+	binary.BigEndian.PutUint32(frame[40:], 0x03)
+	binary.BigEndian.PutUint32(frame[44:], frames)
+	binary.BigEndian.PutUint32(frame[48:], bytes)
+	return frame
+}
+
+// id3v23 returns an ID3v2.3 tag of the frames.
+func id3v23(frames ...[]byte) []byte {
+	body := bytes.Join(frames, nil)
+	body = append(body, make([]byte, 16)...) // padding
+	n := len(body)
+	header := []byte{'I', 'D', '3', 3, 0, 0,
+		byte(n >> 21 & 0x7F), byte(n >> 14 & 0x7F), byte(n >> 7 & 0x7F), byte(n & 0x7F)}
+	return append(header, body...)
+}
+
+// id3v23Frame returns an ID3v2.3 frame.
+func id3v23Frame(id string, data []byte) []byte {
+	header := make([]byte, 10)
+	copy(header, id)
+	binary.BigEndian.PutUint32(header[4:], uint32(len(data)))
+	return append(header, data...)
+}
+
+// utf16Text returns the UTF-16 string with a byte order mark.
+func utf16Text(s string) []byte {
+	b := []byte{0xFF, 0xFE}
+	for _, u := range utf16.Encode([]rune(s)) {
+		b = append(b, byte(u), byte(u>>8))
+	}
+	return b
+}
+
+// box returns an MP4 box of the payloads.
+func box(boxType string, payloads ...[]byte) []byte {
+	payload := bytes.Join(payloads, nil)
+	b := make([]byte, 8, 8+len(payload))
+	binary.BigEndian.PutUint32(b, uint32(8+len(payload)))
+	copy(b[4:], boxType)
+	return append(b, payload...)
+}
+
+// u32 returns the big endian bytes of the integers.
+func u32(values ...uint32) []byte {
+	b := make([]byte, 4*len(values))
+	for n, v := range values {
+		binary.BigEndian.PutUint32(b[4*n:], v)
+	}
+	return b
+}
+
+// writeTemp writes the data to a file of the name in a temporary directory.
+func writeTemp(t *testing.T, name string, data []byte) string {
+	dir, err := ioutil.TempDir("", "probe")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+	path := filepath.Join(dir, name)
+	if err := ioutil.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestFile(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	path := writeTemp(t, "episode.mp3", mp3Frames(mp3Header, mp3FrameLen, 100))
+
+	// act
+	info, err := probe.File(path)
+
+	// assert
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, probe.FormatMP3, info.Format)
+	assert.EqualValues(t, 100*mp3FrameLen, info.Length)
+	assert.Equal(t, 2612244*time.Microsecond, info.Duration.Truncate(time.Microsecond))
+}
+
+func TestFileErrors(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	text := writeTemp(t, "notes.txt", []byte("show notes"))
+
+	// act
+	_, errMissing := probe.File(filepath.Join(filepath.Dir(text), "missing.mp3"))
+	_, errText := probe.File(text)
+
+	// assert
+	assert.Error(t, errMissing)
+	assert.Equal(t, probe.ErrUnsupportedFormat, errors.Cause(errText))
+}
+
+func TestReadEmpty(t *testing.T) {
+	t.Parallel()
+
+	// act
+	_, err := probe.Read(bytes.NewReader(nil), 0)
+
+	// assert
+	assert.Equal(t, probe.ErrUnsupportedFormat, err)
+}
@@ -0,0 +1,60 @@
+package podcast
+
+import (
+	"encoding/xml"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Soundbite represents a podcast:soundbite tag, a short clip of the episode
+// suitable for sharing or previewing.
+//
+// Specification: https://github.com/Podcastindex-org/podcast-namespace/blob/main/docs/1.0.md#soundbite
+type Soundbite struct {
+	XMLName xml.Name `xml:"podcast:soundbite"`
+
+	// StartTime is the offset of the clip from the start of the episode.
+	StartTime time.Duration `xml:"-"`
+	// StartTimeFormatted is the StartTime in seconds.
+	//
+	// This field gets overwritten with the API when setting StartTime.
+	StartTimeFormatted string `xml:"startTime,attr"`
+
+	// Duration is the length of the clip.
+	Duration time.Duration `xml:"-"`
+	// DurationFormatted is the Duration in seconds.
+	//
+	// This field gets overwritten with the API when setting Duration.
+	DurationFormatted string `xml:"duration,attr"`
+
+	// Title is an optional free-form title for the clip.
+	Title string `xml:",chardata"`
+}
+
+// end returns the offset at which the soundbite ends.
+func (s *Soundbite) end() time.Duration {
+	return s.StartTime + s.Duration
+}
+
+// decodeSoundbite restores the typed Soundbite fields from the formatted
+// attributes read from the feed.
+func decodeSoundbite(s *Soundbite) {
+	s.StartTime, _ = parseSeconds(s.StartTimeFormatted)
+	s.Duration, _ = parseSeconds(s.DurationFormatted)
+}
+
+// formatSeconds formats d as a decimal number of seconds, e.g. "73.5".
+func formatSeconds(d time.Duration) string {
+	return strconv.FormatFloat(d.Seconds(), 'f', -1, 64)
+}
+
+// parseSeconds parses a decimal number of seconds as formatted by
+// formatSeconds.
+func parseSeconds(s string) (time.Duration, bool) {
+	f, err := strconv.ParseFloat(strings.TrimSpace(s), 64)
+	if err != nil || f < 0 {
+		return 0, false
+	}
+	return time.Duration(f * float64(time.Second)), true
+}
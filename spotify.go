@@ -0,0 +1,30 @@
+package podcast
+
+import (
+	"encoding/xml"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/text/language"
+)
+
+// SpotifyLimit represents a spotify:limit tag, the number of most recent
+// episodes Spotify shows for the podcast.
+type SpotifyLimit struct {
+	XMLName     xml.Name `xml:"spotify:limit"`
+	RecentCount int      `xml:"recentCount,attr"`
+}
+
+// parseCountryCode returns the lower case ISO 3166-1 alpha-2 code of the
+// country, or an error if it is not a known country.
+func parseCountryCode(country string) (string, error) {
+	country = strings.TrimSpace(country)
+	if len(country) != 2 {
+		return "", errors.New(country + ": country must be an ISO 3166-1 alpha-2 code")
+	}
+	r, err := language.ParseRegion(country)
+	if err != nil || !r.IsCountry() {
+		return "", errors.New(country + ": unknown ISO 3166-1 country code")
+	}
+	return strings.ToLower(r.Canonicalize().String()), nil
+}
@@ -0,0 +1,797 @@
+package podcast
+
+import (
+	"bufio"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/pkg/errors"
+	ext "github.com/podpalinc/rss-feed-generator/parser/extensions"
+)
+
+// ItemIterator yields the Items of a feed one at a time, for feeds too
+// large to hold in Podcast.Items, such as Items read from a database.
+type ItemIterator interface {
+	// Next returns the next Item, or io.EOF once there are no more.
+	Next() (*Item, error)
+}
+
+// ItemIteratorFunc adapts a function to the ItemIterator interface.
+type ItemIteratorFunc func() (*Item, error)
+
+// Next calls f.
+func (f ItemIteratorFunc) Next() (*Item, error) {
+	return f()
+}
+
+// EncodeStream writes the feed as EncodeWithOptions does, byte for byte,
+// without going through the reflection of encoding/xml: the channel and
+// each Item are written to the io.Writer as they go, which is several
+// times faster for large feeds.
+func (p *Podcast) EncodeStream(w io.Writer, o EncodeOptions) error {
+	e := p.encodable(o)
+
+	if o.MaxBytes > 0 {
+		// find the most Items that fit in MaxBytes, counting the bytes
+		// rather than buffering them
+		items := e.Items
+		size := func(n int) (int, error) {
+			var c countingWriter
+			err := streamFeed(&c, e, sliceIterator(items[:n]), o.Minify)
+			return c.n, err
+		}
+		n, err := size(len(items))
+		if err != nil {
+			return err
+		}
+		if n > o.MaxBytes {
+			n = sort.Search(len(items), func(n int) bool {
+				b, err := size(n)
+				return err != nil || b > o.MaxBytes
+			}) - 1
+			if n < 0 {
+				return errors.New("podcast.EncodeStream: feed is larger than MaxBytes " + strconv.Itoa(o.MaxBytes))
+			}
+			items = items[:n]
+		}
+		e.Items = items
+	}
+
+	return streamFeed(w, e, sliceIterator(e.Items), o.Minify)
+}
+
+// EncodeItems writes the feed as EncodeStream does, pulling the Items from
+// the ItemIterator instead of the Items of the Podcast, which are ignored.
+//
+// The Items are written in the order the ItemIterator returns them, so the
+// Order of the EncodeOptions must be ItemOrderInsertion, and MaxBytes is
+// not supported.  As the channel is written before any Item is read,
+// UpdateChannelDates is ignored: set PubDate and LastBuildDate before.
+//
+// An error of the ItemIterator stops the feed where it is, and is returned.
+func (p *Podcast) EncodeItems(w io.Writer, items ItemIterator, o EncodeOptions) error {
+	if o.Order != ItemOrderInsertion {
+		return errors.New("podcast.EncodeItems: Order must be ItemOrderInsertion")
+	}
+	if o.MaxBytes > 0 {
+		return errors.New("podcast.EncodeItems: MaxBytes is not supported")
+	}
+
+	c := *p
+	c.Items = nil
+	e := c.formatted()
+
+	written := 0
+	next := ItemIteratorFunc(func() (*Item, error) {
+		for {
+			if o.MaxItems > 0 && written >= o.MaxItems {
+				return nil, io.EOF
+			}
+			i, err := items.Next()
+			if err != nil {
+				return nil, err
+			}
+			if i == nil || !o.visible(i) {
+				continue
+			}
+			if o.ExcludeBlocked && isYes(i.IBlock) {
+				continue
+			}
+			written++
+			f := *i
+			f.format()
+			return &f, nil
+		}
+	})
+	return streamFeed(w, e, next, o.Minify)
+}
+
+// sliceIterator returns an ItemIterator over the Items.
+func sliceIterator(items []*Item) ItemIterator {
+	return ItemIteratorFunc(func() (*Item, error) {
+		for len(items) > 0 {
+			i := items[0]
+			items = items[1:]
+			if i != nil {
+				return i, nil
+			}
+		}
+		return nil, io.EOF
+	})
+}
+
+// countingWriter counts the bytes written to it.
+type countingWriter struct {
+	n int
+}
+
+func (c *countingWriter) Write(b []byte) (int, error) {
+	c.n += len(b)
+	return len(b), nil
+}
+
+// streamFeed writes the formatted Podcast e, with the Items of next in
+// place of its own.
+func streamFeed(w io.Writer, e *Podcast, next ItemIterator, minify bool) error {
+	s := &streamEncoder{w: bufio.NewWriter(w), indent: !minify}
+	s.w.WriteString(HEADER)
+
+	s.open("rss")
+	s.attr("version", "2.0")
+	s.attrOmit("xmlns:atom", ATOMNS)
+	s.attr("xmlns:itunes", ITUNESNS)
+	s.attr("xmlns:googleplay", GOOGLEPLAYNS)
+	s.attr("xmlns:spotify", SPOTIFYNS)
+	s.attr("xmlns:content", CONTENT)
+	s.attrOmit("xmlns:podcast", PODCASTNS)
+	for _, a := range namespaceAttrs(e.Namespaces) {
+		s.attr(a.Name.Local, a.Value)
+	}
+	s.close()
+
+	s.channel(e)
+	for {
+		i, err := next.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			s.w.Flush()
+			return errors.Wrap(err, "podcast.streamFeed: items.Next returned error")
+		}
+		s.item(i)
+	}
+	s.end("channel")
+	s.end("rss")
+
+	if err := s.w.Flush(); err != nil {
+		return errors.Wrap(err, "podcast.streamFeed: w.Write return error")
+	}
+	return nil
+}
+
+// streamEncoder writes XML exactly as an encoding/xml Encoder, indented
+// by two spaces unless minified, would marshal the same structs.  Write
+// errors are kept by the bufio.Writer and returned by Flush.
+type streamEncoder struct {
+	w *bufio.Writer
+
+	indent     bool
+	depth      int
+	indentedIn bool
+	putNewline bool
+	scratch    []byte
+}
+
+// writeIndent mirrors the indentation of encoding/xml: an element closing
+// right after its text stays on the line it opened on.
+func (s *streamEncoder) writeIndent(depthDelta int) {
+	if !s.indent {
+		return
+	}
+	if depthDelta < 0 {
+		s.depth--
+		if s.indentedIn {
+			s.indentedIn = false
+			return
+		}
+		s.indentedIn = false
+	}
+	if s.putNewline {
+		s.w.WriteByte('\n')
+	} else {
+		s.putNewline = true
+	}
+	for n := 0; n < s.depth; n++ {
+		s.w.WriteString("  ")
+	}
+	if depthDelta > 0 {
+		s.depth++
+		s.indentedIn = true
+	}
+}
+
+// open writes the start tag of the element, up to its attributes.
+func (s *streamEncoder) open(name string) {
+	s.writeIndent(1)
+	s.w.WriteByte('<')
+	s.w.WriteString(name)
+}
+
+// attr writes the attribute of the start tag being opened.
+func (s *streamEncoder) attr(name, value string) {
+	s.w.WriteByte(' ')
+	s.w.WriteString(name)
+	s.w.WriteString(`="`)
+	s.escape(value)
+	s.w.WriteByte('"')
+}
+
+// attrOmit writes the attribute unless its value is empty.
+func (s *streamEncoder) attrOmit(name, value string) {
+	if len(value) > 0 {
+		s.attr(name, value)
+	}
+}
+
+// attrInt writes the integer attribute.
+func (s *streamEncoder) attrInt(name string, value int64) {
+	s.scratch = strconv.AppendInt(s.scratch[:0], value, 10)
+	s.w.WriteByte(' ')
+	s.w.WriteString(name)
+	s.w.WriteString(`="`)
+	s.w.Write(s.scratch)
+	s.w.WriteByte('"')
+}
+
+// attrIntOmit writes the integer attribute unless it is zero.
+func (s *streamEncoder) attrIntOmit(name string, value int64) {
+	if value != 0 {
+		s.attrInt(name, value)
+	}
+}
+
+// close ends the start tag being opened.
+func (s *streamEncoder) close() {
+	s.w.WriteByte('>')
+}
+
+// end writes the end tag of the element.
+func (s *streamEncoder) end(name string) {
+	s.writeIndent(-1)
+	s.w.WriteString("</")
+	s.w.WriteString(name)
+	s.w.WriteByte('>')
+}
+
+// text writes the element holding the escaped text.
+func (s *streamEncoder) text(name, value string) {
+	s.open(name)
+	s.close()
+	s.escape(value)
+	s.end(name)
+}
+
+// textOmit writes the text element unless the text is empty.
+func (s *streamEncoder) textOmit(name, value string) {
+	if len(value) > 0 {
+		s.text(name, value)
+	}
+}
+
+// cdata writes the element holding the text in a CDATA section.
+func (s *streamEncoder) cdata(name, value string) {
+	s.open(name)
+	s.close()
+	s.writeCDATA(value)
+	s.end(name)
+}
+
+// writeCDATA writes the text in a CDATA section, unless it is empty.
+func (s *streamEncoder) writeCDATA(value string) {
+	if len(value) == 0 {
+		return
+	}
+	s.w.WriteString("<![CDATA[")
+	for {
+		n := strings.Index(value, "]]>")
+		if n < 0 {
+			break
+		}
+		s.w.WriteString(value[:n])
+		s.w.WriteString("]]]]><![CDATA[>")
+		value = value[n+3:]
+	}
+	s.w.WriteString(value)
+	s.w.WriteString("]]>")
+}
+
+// escape writes the value escaped as xml.EscapeText does.
+func (s *streamEncoder) escape(value string) {
+	last := 0
+	for n := 0; n < len(value); {
+		r, width := utf8.DecodeRuneInString(value[n:])
+		n += width
+		var esc string
+		switch r {
+		case '"':
+			esc = "&#34;"
+		case '\'':
+			esc = "&#39;"
+		case '&':
+			esc = "&amp;"
+		case '<':
+			esc = "&lt;"
+		case '>':
+			esc = "&gt;"
+		case '\t':
+			esc = "&#x9;"
+		case '\n':
+			esc = "&#xA;"
+		case '\r':
+			esc = "&#xD;"
+		default:
+			if !isXMLChar(r) || (r == utf8.RuneError && width == 1) {
+				esc = "\uFFFD"
+				break
+			}
+			continue
+		}
+		s.w.WriteString(value[last : n-width])
+		s.w.WriteString(esc)
+		last = n
+	}
+	s.w.WriteString(value[last:])
+}
+
+// isXMLChar reports whether the rune is in the Char production of the XML
+// specification.
+func isXMLChar(r rune) bool {
+	return r == 0x09 ||
+		r == 0x0A ||
+		r == 0x0D ||
+		r >= 0x20 && r <= 0xD7FF ||
+		r >= 0xE000 && r <= 0xFFFD ||
+		r >= 0x10000 && r <= 0x10FFFF
+}
+
+func (s *streamEncoder) channel(p *Podcast) {
+	s.open("channel")
+	s.close()
+
+	s.atomLink(p.AtomLink)
+	s.textOmit("generator", p.Generator)
+	s.text("title", p.Title)
+	s.textOmit("link", p.Link)
+	s.description(p.Description)
+	s.textOmit("language", p.Language)
+	s.textOmit("cloud", p.Cloud)
+	s.textOmit("copyright", p.Copyright)
+	s.textOmit("docs", p.Docs)
+	s.textOmit("pubDate", p.PubDate)
+	s.textOmit("lastBuildDate", p.LastBuildDate)
+	s.textOmit("managingEditor", p.ManagingEditor)
+	s.textOmit("rating", p.Rating)
+	s.textOmit("skipHours", p.SkipHours)
+	s.textOmit("skipDays", p.SkipDays)
+	if p.TTL != 0 {
+		s.text("ttl", strconv.Itoa(p.TTL))
+	}
+	s.textOmit("webMaster", p.WebMaster)
+	s.image(p.Image)
+	s.textInput(p.TextInput)
+
+	s.textOmit("itunes:title", p.ITitle)
+	s.textOmit("itunes:author", p.IAuthor)
+	s.textOmit("itunes:subtitle", p.ISubtitle)
+	s.textOmit("itunes:type", p.IType)
+	s.iSummary(p.ISummary)
+	s.textOmit("itunes:block", p.IBlock)
+	s.iImage(p.IImage)
+	s.textOmit("itunes:duration", p.IDuration)
+	s.textOmit("itunes:explicit", p.IExplicit)
+	s.textOmit("itunes:complete", p.IComplete)
+	s.textOmit("itunes:new-feed-url", p.INewFeedURL)
+	s.owner(p.IOwner)
+	for _, c := range p.ICategories {
+		s.iCategory(c)
+	}
+
+	s.locked(p.Locked)
+	s.textOmit("podcast:guid", p.GUID)
+	for _, person := range p.Persons {
+		s.person(person)
+	}
+	for _, f := range p.Funding {
+		s.funding(f)
+	}
+	for _, v := range p.Values {
+		s.value(v)
+	}
+	for _, li := range p.LiveItems {
+		s.liveItem(li)
+	}
+
+	s.textOmit("googleplay:author", p.GooglePlayAuthor)
+	s.textOmit("googleplay:description", p.GooglePlayDescription)
+	s.textOmit("googleplay:email", p.GooglePlayEmail)
+	if p.GooglePlayImage != nil {
+		s.open("googleplay:image")
+		s.attr("href", p.GooglePlayImage.HREF)
+		s.close()
+		s.end("googleplay:image")
+	}
+	for _, c := range p.GooglePlayCategories {
+		s.googlePlayCategory(c)
+	}
+	s.textOmit("googleplay:explicit", p.GooglePlayExplicit)
+	s.textOmit("googleplay:block", p.GooglePlayBlock)
+
+	if p.SpotifyLimit != nil {
+		s.open("spotify:limit")
+		s.attrInt("recentCount", int64(p.SpotifyLimit.RecentCount))
+		s.close()
+		s.end("spotify:limit")
+	}
+	s.textOmit("spotify:countryOfOrigin", p.SpotifyCountryOfOrigin)
+
+	for _, x := range p.Extensions {
+		if x != nil {
+			s.extension(x.Prefix, x.Extension, x.CDATA)
+		}
+	}
+}
+
+func (s *streamEncoder) item(i *Item) {
+	s.open("item")
+	s.close()
+	s.itemFields(i)
+	s.end("item")
+}
+
+func (s *streamEncoder) liveItem(li *LiveItem) {
+	if li == nil {
+		return
+	}
+	s.open("podcast:liveItem")
+	s.attr("status", li.Status)
+	s.attr("start", li.StartFormatted)
+	s.attrOmit("end", li.EndFormatted)
+	s.close()
+	s.itemFields(&li.Item)
+	for _, cl := range li.ContentLinks {
+		if cl == nil {
+			continue
+		}
+		s.open("podcast:contentLink")
+		s.attr("href", cl.Href)
+		s.close()
+		s.escape(cl.Text)
+		s.end("podcast:contentLink")
+	}
+	s.end("podcast:liveItem")
+}
+
+func (s *streamEncoder) itemFields(i *Item) {
+	if i.GUID != nil {
+		s.open("guid")
+		s.attr("isPermaLink", strconv.FormatBool(i.GUID.IsPermaLink))
+		s.close()
+		s.escape(i.GUID.Value)
+		s.end("guid")
+	}
+	s.text("title", i.Title)
+	s.text("link", i.Link)
+	s.description(i.Description)
+	if i.EncodedDescription != nil {
+		s.cdata("content:encoded", i.EncodedDescription.Text)
+	}
+	s.textOmit("author", i.AuthorFormatted)
+	s.textOmit("category", i.Category)
+	s.textOmit("comments", i.Comments)
+	s.textOmit("source", i.Source)
+	s.textOmit("pubDate", i.PubDate)
+	if i.Enclosure != nil {
+		s.open("enclosure")
+		s.attr("url", i.Enclosure.URL)
+		s.attr("length", i.Enclosure.LengthFormatted)
+		s.attr("type", i.Enclosure.TypeFormatted)
+		s.close()
+		s.end("enclosure")
+	}
+
+	s.textOmit("itunes:author", i.IAuthor)
+	s.textOmit("itunes:title", i.ITitle)
+	s.textOmit("itunes:season", i.SeasonNumber)
+	s.textOmit("itunes:episode", i.EpisodeNumber)
+	s.textOmit("itunes:episodeType", i.EpisodeType)
+	s.textOmit("itunes:subtitle", i.ISubtitle)
+	s.iSummary(i.ISummary)
+	s.iImage(i.IImage)
+	s.textOmit("itunes:block", i.IBlock)
+	s.textOmit("itunes:duration", i.IDuration)
+	s.textOmit("itunes:explicit", i.IExplicit)
+	s.textOmit("itunes:isClosedCaptioned", i.IIsClosedCaptioned)
+	s.textOmit("itunes:order", i.IOrder)
+
+	s.textOmit("googleplay:description", i.GooglePlayDescription)
+	s.textOmit("googleplay:explicit", i.GooglePlayExplicit)
+	s.textOmit("googleplay:block", i.GooglePlayBlock)
+
+	for _, t := range i.Transcripts {
+		if t == nil {
+			continue
+		}
+		s.open("podcast:transcript")
+		s.attr("url", t.URL)
+		s.attr("type", t.Type)
+		s.attrOmit("language", t.Language)
+		s.attrOmit("rel", t.Rel)
+		s.close()
+		s.end("podcast:transcript")
+	}
+	if i.Chapters != nil {
+		s.open("podcast:chapters")
+		s.attr("url", i.Chapters.URL)
+		s.attr("type", i.Chapters.Type)
+		s.close()
+		s.end("podcast:chapters")
+	}
+	for _, sb := range i.Soundbites {
+		if sb == nil {
+			continue
+		}
+		s.open("podcast:soundbite")
+		s.attr("startTime", sb.StartTimeFormatted)
+		s.attr("duration", sb.DurationFormatted)
+		s.close()
+		s.escape(sb.Title)
+		s.end("podcast:soundbite")
+	}
+	for _, person := range i.Persons {
+		s.person(person)
+	}
+	for _, f := range i.Funding {
+		s.funding(f)
+	}
+	for _, v := range i.Values {
+		s.value(v)
+	}
+	for _, ae := range i.AlternateEnclosures {
+		s.alternateEnclosure(ae)
+	}
+	for _, x := range i.Extensions {
+		if x != nil {
+			s.extension(x.Prefix, x.Extension, x.CDATA)
+		}
+	}
+}
+
+func (s *streamEncoder) atomLink(l *AtomLink) {
+	if l == nil {
+		return
+	}
+	s.open("atom:link")
+	s.attr("href", l.HREF)
+	s.attr("rel", l.Rel)
+	s.attr("type", l.Type)
+	s.close()
+	s.end("atom:link")
+}
+
+func (s *streamEncoder) description(d *Description) {
+	if d != nil {
+		s.cdata("description", d.Text)
+	}
+}
+
+func (s *streamEncoder) image(i *Image) {
+	if i == nil {
+		return
+	}
+	s.open("image")
+	s.close()
+	s.text("url", i.URL)
+	s.text("title", i.Title)
+	s.textOmit("link", i.Link)
+	s.textOmit("description", i.Description)
+	if i.Width != 0 {
+		s.text("width", strconv.Itoa(i.Width))
+	}
+	if i.Height != 0 {
+		s.text("height", strconv.Itoa(i.Height))
+	}
+	s.end("image")
+}
+
+func (s *streamEncoder) textInput(t *TextInput) {
+	if t == nil {
+		return
+	}
+	s.open("textInput")
+	s.close()
+	s.text("title", t.Title)
+	s.text("description", t.Description)
+	s.text("name", t.Name)
+	s.text("link", t.Link)
+	s.end("textInput")
+}
+
+func (s *streamEncoder) iSummary(i *ISummary) {
+	if i != nil {
+		s.cdata("itunes:summary", i.Text)
+	}
+}
+
+func (s *streamEncoder) iImage(i *IImage) {
+	if i == nil {
+		return
+	}
+	s.open("itunes:image")
+	s.attr("href", i.HREF)
+	s.close()
+	s.end("itunes:image")
+}
+
+func (s *streamEncoder) owner(a *Author) {
+	if a == nil {
+		return
+	}
+	s.open("itunes:owner")
+	s.close()
+	s.text("itunes:name", a.Name)
+	s.text("itunes:email", a.Email)
+	s.end("itunes:owner")
+}
+
+func (s *streamEncoder) iCategory(c *ICategory) {
+	if c == nil {
+		return
+	}
+	s.open("itunes:category")
+	s.attr("text", c.Text)
+	s.close()
+	for _, sub := range c.ICategories {
+		s.iCategory(sub)
+	}
+	s.end("itunes:category")
+}
+
+func (s *streamEncoder) googlePlayCategory(c *GooglePlayCategory) {
+	if c == nil {
+		return
+	}
+	s.open("googleplay:category")
+	s.attr("text", c.Text)
+	s.close()
+	for _, sub := range c.GooglePlayCategories {
+		s.googlePlayCategory(sub)
+	}
+	s.end("googleplay:category")
+}
+
+func (s *streamEncoder) locked(l *Locked) {
+	if l == nil {
+		return
+	}
+	s.open("podcast:locked")
+	s.attrOmit("owner", l.Owner)
+	s.close()
+	s.escape(l.Value)
+	s.end("podcast:locked")
+}
+
+func (s *streamEncoder) person(p *Person) {
+	if p == nil {
+		return
+	}
+	s.open("podcast:person")
+	s.attrOmit("role", p.Role)
+	s.attrOmit("group", p.Group)
+	s.attrOmit("img", p.Img)
+	s.attrOmit("href", p.Href)
+	s.close()
+	s.escape(p.Name)
+	s.end("podcast:person")
+}
+
+func (s *streamEncoder) funding(f *Funding) {
+	if f == nil {
+		return
+	}
+	s.open("podcast:funding")
+	s.attr("url", f.URL)
+	s.close()
+	s.escape(f.Text)
+	s.end("podcast:funding")
+}
+
+func (s *streamEncoder) value(v *Value) {
+	if v == nil {
+		return
+	}
+	s.open("podcast:value")
+	s.attr("type", v.Type)
+	s.attr("method", v.Method)
+	s.attrOmit("suggested", v.Suggested)
+	s.close()
+	for _, r := range v.Recipients {
+		if r == nil {
+			continue
+		}
+		s.open("podcast:valueRecipient")
+		s.attrOmit("name", r.Name)
+		s.attrOmit("customKey", r.CustomKey)
+		s.attrOmit("customValue", r.CustomValue)
+		s.attr("type", r.Type)
+		s.attr("address", r.Address)
+		s.attrInt("split", int64(r.Split))
+		if r.Fee {
+			s.attr("fee", "true")
+		}
+		s.close()
+		s.end("podcast:valueRecipient")
+	}
+	s.end("podcast:value")
+}
+
+func (s *streamEncoder) alternateEnclosure(ae *AlternateEnclosure) {
+	if ae == nil {
+		return
+	}
+	s.open("podcast:alternateEnclosure")
+	s.attr("type", ae.Type)
+	s.attrIntOmit("length", ae.Length)
+	s.attrIntOmit("bitrate", ae.Bitrate)
+	s.attrIntOmit("height", int64(ae.Height))
+	s.attrOmit("lang", ae.Language)
+	s.attrOmit("title", ae.Title)
+	s.attrOmit("rel", ae.Rel)
+	s.attrOmit("codecs", ae.Codecs)
+	if ae.Default {
+		s.attr("default", "true")
+	}
+	s.close()
+	for _, src := range ae.Sources {
+		if src == nil {
+			continue
+		}
+		s.open("podcast:source")
+		s.attr("uri", src.URI)
+		s.attrOmit("contentType", src.ContentType)
+		s.close()
+		s.end("podcast:source")
+	}
+	if ae.Integrity != nil {
+		s.open("podcast:integrity")
+		s.attr("type", ae.Integrity.Type)
+		s.attr("value", ae.Integrity.Value)
+		s.close()
+		s.end("podcast:integrity")
+	}
+	s.end("podcast:alternateEnclosure")
+}
+
+func (s *streamEncoder) extension(prefix string, e ext.Extension, cdata bool) {
+	if len(e.Name) == 0 {
+		return
+	}
+	name := qualifiedName(prefix, e.Name)
+	s.open(name)
+	for _, a := range sortedAttrs(e.Attrs) {
+		s.attr(a.Name.Local, a.Value)
+	}
+	s.close()
+	if cdata {
+		s.writeCDATA(e.Value)
+	} else {
+		s.escape(e.Value)
+	}
+	for _, c := range sortedChildren(e.Children) {
+		s.extension(prefix, c, false)
+	}
+	s.end(name)
+}
@@ -0,0 +1,319 @@
+package podcast_test
+
+import (
+	"bytes"
+	"errors"
+	"io"
+	"io/ioutil"
+	"reflect"
+	"strconv"
+	"testing"
+	"time"
+
+	podcast "github.com/podpalinc/rss-feed-generator"
+	ext "github.com/podpalinc/rss-feed-generator/parser/extensions"
+	"github.com/stretchr/testify/assert"
+)
+
+// awkward text exercising the escaping of encoding/xml
+const streamTestText = "a <b> & \"c\" 'd'\t\n\r\x01 ]]> \xff é"
+
+func newStreamTestPodcast(t testing.TB, items int) podcast.Podcast {
+	now := time.Date(2021, time.March, 14, 12, 0, 0, 0, time.UTC)
+	p := podcast.New("title "+streamTestText, "http://example.com/", podcast.Description{Text: streamTestText}, &now, &now)
+	p.AddAtomLink("http://example.com/feed.xml")
+	p.AddGenerator("generator")
+	p.AddLanguage("en-us")
+	p.AddCopyright(streamTestText)
+	p.AddAuthor([]string{"Jane Doe"})
+	p.AddOwner("Jane Doe", "jane@example.com")
+	p.AddImage("http://example.com/artwork.png")
+	p.AddSummary(streamTestText)
+	p.AddSubTitle("subtitle")
+	p.AddItunesType("serial")
+	p.AddExplicit(false)
+	p.AddLocked(true, "jane@example.com")
+	p.AddFunding("http://example.com/donate", streamTestText)
+	p.AddSpotifyLimit(10)
+	p.TTL = 60
+	p.Image = &podcast.Image{URL: "http://example.com/artwork.png", Title: "title", Width: 144}
+	p.TextInput = &podcast.TextInput{Title: "search", Name: "q"}
+	p.Persons = []*podcast.Person{{Name: streamTestText, Role: "host"}, nil}
+	p.Values = []*podcast.Value{{
+		Type:   "lightning",
+		Method: "keysend",
+		Recipients: []*podcast.ValueRecipient{
+			{Name: "host", Type: "node", Address: "abc", Split: 99},
+			{Type: "node", Address: "def", Split: 1, Fee: true},
+		},
+	}}
+	if err := p.AddCategory("Arts", []string{"Books", "Design"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddCategory("True Crime", nil); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddSpotifyCountryOfOrigin("us", "ca"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddNamespace("media", "http://search.yahoo.com/mrss/"); err != nil {
+		t.Fatal(err)
+	}
+	if err := p.AddExtension("media", ext.Extension{Name: "rating", Value: streamTestText, Attrs: map[string]string{"scheme": "urn:simple"}}); err != nil {
+		t.Fatal(err)
+	}
+
+	li := podcast.LiveItem{Status: podcast.LiveItemStatusLive, StartFormatted: "2021-03-14T12:00:00Z"}
+	li.Title = "live"
+	li.GUID = &podcast.GUID{Value: "live-1"}
+	li.ContentLinks = []*podcast.ContentLink{{Href: "http://example.com/live", Text: streamTestText}}
+	p.LiveItems = []*podcast.LiveItem{&li}
+
+	for n := 1; n <= items; n++ {
+		i := podcast.Item{
+			Title:       "episode " + strconv.Itoa(n),
+			Description: &podcast.Description{Text: streamTestText},
+		}
+		i.AddEnclosure("http://example.com/"+strconv.Itoa(n)+".mp3", podcast.MP3, "", 183)
+		i.AddPubDateTime(now.Add(time.Duration(n-items) * time.Hour))
+		i.AddDurationTime(42 * time.Minute)
+		i.AddSeason(1)
+		i.AddEpisode(n)
+		i.AddSummary(streamTestText)
+		i.AddImage("http://example.com/" + strconv.Itoa(n) + ".png")
+		i.AddChapters("http://example.com/chapters.json", podcast.ChaptersTypeJSON)
+		i.AddFunding("http://example.com/donate", "")
+		if err := i.AddExtension("media", ext.Extension{
+			Name:  "content",
+			Attrs: map[string]string{"url": "http://example.com/" + strconv.Itoa(n) + ".mp4", "medium": "video"},
+			Children: map[string][]ext.Extension{
+				"title":     {{Name: "title", Value: streamTestText}},
+				"thumbnail": {{Name: "thumbnail", Attrs: map[string]string{"url": "http://example.com/t.png"}}},
+			},
+		}); err != nil {
+			t.Fatal(err)
+		}
+		i.Extensions = append(i.Extensions, &podcast.Extension{Prefix: "media", Extension: ext.Extension{Name: "text", Value: streamTestText}, CDATA: true})
+		if n%2 == 0 {
+			i.AddExplicit(true)
+			i.IBlock = "yes"
+			i.EncodedDescription = &podcast.EncodedContent{Text: "<p>" + streamTestText + "</p>"}
+			if err := i.AddTranscript("http://example.com/t.vtt", podcast.TranscriptTypeVTT, "en", true); err != nil {
+				t.Fatal(err)
+			}
+			if err := i.AddSoundbite(time.Minute, 30*time.Second, streamTestText); err != nil {
+				t.Fatal(err)
+			}
+			if err := i.AddAlternateEnclosure(podcast.AlternateEnclosure{
+				Type:      "audio/opus",
+				Length:    1000,
+				Default:   true,
+				Sources:   []*podcast.Source{{URI: "http://example.com/" + strconv.Itoa(n) + ".opus"}},
+				Integrity: &podcast.Integrity{Type: "sri", Value: "sha384-abc"},
+			}); err != nil {
+				t.Fatal(err)
+			}
+		}
+		if _, err := p.AddItem(i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return p
+}
+
+func TestEncodeStreamIdentical(t *testing.T) {
+	t.Parallel()
+
+	now := time.Date(2021, time.March, 14, 12, 0, 0, 0, time.UTC)
+	clock := func() time.Time { return now }
+	tests := []struct {
+		name string
+		o    podcast.EncodeOptions
+	}{
+		{"default", podcast.EncodeOptions{}},
+		{"minify", podcast.EncodeOptions{Minify: true}},
+		{"ordered", podcast.EncodeOptions{Clock: clock, Order: podcast.ItemOrderNewestFirst, MaxItems: 3}},
+		{"blocked", podcast.EncodeOptions{Clock: clock, ExcludeBlocked: true}},
+		{"max bytes", podcast.EncodeOptions{Clock: clock, MaxBytes: 12000}},
+	}
+	for _, tt := range tests {
+		tt := tt
+		t.Run(tt.name, func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			p := newStreamTestPodcast(t, 10)
+			var want, got bytes.Buffer
+
+			// act
+			errWant := p.EncodeWithOptions(&want, tt.o)
+			errGot := p.EncodeStream(&got, tt.o)
+
+			// assert
+			assert.NoError(t, errWant)
+			assert.NoError(t, errGot)
+			assert.Equal(t, want.String(), got.String())
+		})
+	}
+}
+
+func TestEncodeStreamEveryField(t *testing.T) {
+	t.Parallel()
+
+	// the shallower depths leave the nested structs empty
+	for depth := 1; depth <= 8; depth++ {
+		depth := depth
+		t.Run(strconv.Itoa(depth), func(t *testing.T) {
+			t.Parallel()
+
+			// arrange
+			var p podcast.Podcast
+			fillFields(reflect.ValueOf(&p).Elem(), depth)
+			for _, i := range p.Items {
+				if i != nil {
+					i.State = podcast.ItemStatePublished
+				}
+			}
+			var want, got bytes.Buffer
+
+			// act
+			errWant := p.EncodeWithOptions(&want, podcast.EncodeOptions{})
+			errGot := p.EncodeStream(&got, podcast.EncodeOptions{})
+
+			// assert
+			assert.NoError(t, errWant)
+			assert.NoError(t, errGot)
+			assert.Equal(t, want.String(), got.String())
+		})
+	}
+}
+
+func TestEncodeStreamEmpty(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("", "", podcast.Description{}, nil, nil)
+	var want, got bytes.Buffer
+
+	// act
+	errWant := p.Encode(&want)
+	errGot := p.EncodeStream(&got, podcast.EncodeOptions{})
+
+	// assert
+	assert.NoError(t, errWant)
+	assert.NoError(t, errGot)
+	assert.Equal(t, want.String(), got.String())
+}
+
+func TestEncodeStreamMaxBytesError(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newStreamTestPodcast(t, 1)
+	var b bytes.Buffer
+
+	// act
+	err := p.EncodeStream(&b, podcast.EncodeOptions{MaxBytes: 100})
+
+	// assert
+	assert.EqualError(t, err, "podcast.EncodeStream: feed is larger than MaxBytes 100")
+}
+
+func TestEncodeItems(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newStreamTestPodcast(t, 6)
+	items := p.Items
+	p.Items = nil
+	i := 0
+	next := podcast.ItemIteratorFunc(func() (*podcast.Item, error) {
+		if i == len(items) {
+			return nil, io.EOF
+		}
+		i++
+		return items[i-1], nil
+	})
+	o := podcast.EncodeOptions{ExcludeBlocked: true, MaxItems: 2}
+	var want, got bytes.Buffer
+
+	// act
+	errGot := p.EncodeItems(&got, next, o)
+	p.Items = items
+	errWant := p.EncodeWithOptions(&want, o)
+
+	// assert
+	assert.NoError(t, errWant)
+	assert.NoError(t, errGot)
+	assert.Equal(t, want.String(), got.String())
+	assert.Equal(t, 3, i)
+}
+
+func TestEncodeItemsError(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newStreamTestPodcast(t, 0)
+	next := podcast.ItemIteratorFunc(func() (*podcast.Item, error) {
+		return nil, errors.New("connection lost")
+	})
+	var b bytes.Buffer
+
+	// act
+	err := p.EncodeItems(&b, next, podcast.EncodeOptions{})
+	errOrder := p.EncodeItems(&b, next, podcast.EncodeOptions{Order: podcast.ItemOrderSerial})
+	errMaxBytes := p.EncodeItems(&b, next, podcast.EncodeOptions{MaxBytes: 1000})
+
+	// assert
+	assert.EqualError(t, err, "podcast.streamFeed: items.Next returned error: connection lost")
+	assert.EqualError(t, errOrder, "podcast.EncodeItems: Order must be ItemOrderInsertion")
+	assert.EqualError(t, errMaxBytes, "podcast.EncodeItems: MaxBytes is not supported")
+}
+
+func benchmarkPodcast(b *testing.B) podcast.Podcast {
+	b.Helper()
+	return newStreamTestPodcast(b, 10000)
+}
+
+func BenchmarkEncode10k(b *testing.B) {
+	p := benchmarkPodcast(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if err := p.Encode(ioutil.Discard); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeStream10k(b *testing.B) {
+	p := benchmarkPodcast(b)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if err := p.EncodeStream(ioutil.Discard, podcast.EncodeOptions{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkEncodeItems10k(b *testing.B) {
+	p := benchmarkPodcast(b)
+	items := p.Items
+	p.Items = nil
+	b.ReportAllocs()
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		i := 0
+		next := podcast.ItemIteratorFunc(func() (*podcast.Item, error) {
+			if i == len(items) {
+				return nil, io.EOF
+			}
+			i++
+			return items[i-1], nil
+		})
+		if err := p.EncodeItems(ioutil.Discard, next, podcast.EncodeOptions{}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
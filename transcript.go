@@ -0,0 +1,40 @@
+package podcast
+
+import "encoding/xml"
+
+// Transcript MIME types supported by the podcast:transcript tag.
+const (
+	TranscriptTypeVTT  = "text/vtt"
+	TranscriptTypeSRT  = "application/x-subrip"
+	TranscriptTypeJSON = "application/json"
+	TranscriptTypeHTML = "text/html"
+)
+
+// TranscriptRelCaptions marks a transcript as closed captions that are
+// time-coded and suitable for display while the episode plays.
+const TranscriptRelCaptions = "captions"
+
+// Transcript represents a podcast:transcript tag linking an episode to a
+// transcript or closed captions file.
+//
+// Specification: https://github.com/Podcastindex-org/podcast-namespace/blob/main/docs/1.0.md#transcript
+type Transcript struct {
+	XMLName  xml.Name `xml:"podcast:transcript"`
+	URL      string   `xml:"url,attr"`
+	Type     string   `xml:"type,attr"`
+	Language string   `xml:"language,attr,omitempty"`
+	Rel      string   `xml:"rel,attr,omitempty"`
+}
+
+// isCaptions reports whether the transcript is usable as closed captions.
+func (t *Transcript) isCaptions() bool {
+	return t != nil && t.Rel == TranscriptRelCaptions
+}
+
+func isTranscriptType(transcriptType string) bool {
+	switch transcriptType {
+	case TranscriptTypeVTT, TranscriptTypeSRT, TranscriptTypeJSON, TranscriptTypeHTML:
+		return true
+	}
+	return false
+}
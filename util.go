@@ -1,19 +1,49 @@
 package podcast
 
-import "strings"
+import (
+	"strings"
+	"unicode/utf8"
+)
 
-/*
- * Ensures that the string passed in is compliant with RSS feed requirements specified in https://help.apple.com/itc/podcasts_connect/#/itc1723472cb
- */
+// GenerateFeedString returns the string cleaned up for the feed, as
+// required by https://help.apple.com/itc/podcasts_connect/#/itc1723472cb
+//
+// The fields of the structs hold raw text: the encoder escapes &, <, >, '
+// and " when the feed is written, so GenerateFeedString must not, or they
+// would be escaped twice.  It only drops the characters XML 1.0 does not
+// allow, such as control characters other than tab, newline and carriage
+// return, and invalid UTF-8.
+//
+// ©, ℗ and ™ are kept as characters, which the UTF-8 feed carries as-is.
+// Entity and character references, such as &copy; or &amp;, are raw text
+// too and written escaped: pass the characters instead.
 func GenerateFeedString(str string) string {
-	// TODO: Add necessary replacements for Gplay and Spotify too.
-	str = strings.Replace(str, "&", "&amp;", -1)
-	str = strings.Replace(str, "<", "&lt;", -1)
-	str = strings.Replace(str, ">", "&gt;", -1)
-	str = strings.Replace(str, "'", "&apos;", -1)
-	str = strings.Replace(str, "\"", "&quot;", -1)
-	str = strings.Replace(str, "©", "&#xA9;", -1)
-	str = strings.Replace(str, "℗", "&#x2117;", -1)
-	str = strings.Replace(str, "™", "&#x2122;", -1)
-	return str
+	if isValidXML(str) {
+		return str
+	}
+
+	var b strings.Builder
+	b.Grow(len(str))
+	for len(str) > 0 {
+		r, width := utf8.DecodeRuneInString(str)
+		if isXMLChar(r) && !(r == utf8.RuneError && width == 1) {
+			b.WriteString(str[:width])
+		}
+		str = str[width:]
+	}
+	return b.String()
+}
+
+// isValidXML reports whether the string holds only characters XML 1.0
+// allows.
+func isValidXML(str string) bool {
+	if !utf8.ValidString(str) {
+		return false
+	}
+	for _, r := range str {
+		if !isXMLChar(r) {
+			return false
+		}
+	}
+	return true
 }
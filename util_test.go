@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	podcast "github.com/podpalinc/rss-feed-generator"
+	"github.com/podpalinc/rss-feed-generator/parser"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -20,7 +21,7 @@ func TestGenerateStringAmpersand(t *testing.T) {
 
 	result := podcast.GenerateFeedString("Kids & Family")
 
-	assert.Equal(t, result, "Kids &amp; Family")
+	assert.Equal(t, result, "Kids & Family")
 
 }
 
@@ -29,7 +30,7 @@ func TestGenerateStringLessThan(t *testing.T) {
 
 	result := podcast.GenerateFeedString("1 < 3")
 
-	assert.Equal(t, result, "1 &lt; 3")
+	assert.Equal(t, result, "1 < 3")
 }
 
 func TestGenerateStringGreaterThan(t *testing.T) {
@@ -37,7 +38,7 @@ func TestGenerateStringGreaterThan(t *testing.T) {
 
 	result := podcast.GenerateFeedString("3 > 1")
 
-	assert.Equal(t, result, "3 &gt; 1")
+	assert.Equal(t, result, "3 > 1")
 }
 
 func TestGenerateStringApostrophe(t *testing.T) {
@@ -45,7 +46,7 @@ func TestGenerateStringApostrophe(t *testing.T) {
 
 	result := podcast.GenerateFeedString("Sophie's choice")
 
-	assert.Equal(t, result, "Sophie&apos;s choice")
+	assert.Equal(t, result, "Sophie's choice")
 }
 
 func TestGenerateStringQuotation(t *testing.T) {
@@ -53,7 +54,7 @@ func TestGenerateStringQuotation(t *testing.T) {
 
 	result := podcast.GenerateFeedString("He said \"what\"")
 
-	assert.Equal(t, result, "He said &quot;what&quot;")
+	assert.Equal(t, result, "He said \"what\"")
 }
 
 func TestGenerateStringCopyrightSign(t *testing.T) {
@@ -61,7 +62,7 @@ func TestGenerateStringCopyrightSign(t *testing.T) {
 
 	result := podcast.GenerateFeedString("© Podpal Inc, 2020")
 
-	assert.Equal(t, result, "&#xA9; Podpal Inc, 2020")
+	assert.Equal(t, result, "© Podpal Inc, 2020")
 }
 
 func TestGenerateStringSoundRecordingCopyright(t *testing.T) {
@@ -69,7 +70,7 @@ func TestGenerateStringSoundRecordingCopyright(t *testing.T) {
 
 	result := podcast.GenerateFeedString("℗ Podpal Inc, 2020")
 
-	assert.Equal(t, result, "&#x2117; Podpal Inc, 2020")
+	assert.Equal(t, result, "℗ Podpal Inc, 2020")
 }
 
 func TestGenerateStringTrademark(t *testing.T) {
@@ -77,5 +78,57 @@ func TestGenerateStringTrademark(t *testing.T) {
 
 	result := podcast.GenerateFeedString("™ Podpal Inc, 2020")
 
-	assert.Equal(t, result, "&#x2122; Podpal Inc, 2020")
+	assert.Equal(t, result, "™ Podpal Inc, 2020")
+}
+
+func TestGenerateStringControlCharacters(t *testing.T) {
+	t.Parallel()
+
+	result := podcast.GenerateFeedString("Line\x00 one\x08\tand\r\ntwo\x1b\uFFFE \xff")
+
+	assert.Equal(t, "Line one\tand\r\ntwo ", result)
+}
+
+func TestGenerateStringCharacterReferences(t *testing.T) {
+	t.Parallel()
+
+	result := podcast.GenerateFeedString("&#xA9; &copy; &#x2117; &#x2122; &trade; &amp;")
+
+	assert.Equal(t, "&#xA9; &copy; &#x2117; &#x2122; &trade; &amp;", result)
+}
+
+func TestGenerateStringParserRoundTrip(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	title := `Q&A: <Tips> & "Tricks" for Sophie's © ℗ ™ show`
+	p := podcast.New(title, "http://example.com/", podcast.Description{Text: "Description"}, nil, nil)
+	p.AddAuthor([]string{"Aaron's & Co"})
+	p.AddCopyright("© 2020 Podpal & Co")
+	if err := p.AddCategory("Religion & Spirituality", []string{"Christianity"}); err != nil {
+		t.Fatal(err)
+	}
+	i := podcast.Item{Title: title, Description: &podcast.Description{Text: "Description"}}
+	i.AddEnclosure("http://example.com/1.mp3", podcast.MP3, "", 183)
+	if _, err := p.AddItem(i); err != nil {
+		t.Fatal(err)
+	}
+
+	// act
+	feed, err := parser.NewParser().ParseString(p.String())
+
+	// assert
+	if !assert.NoError(t, err) {
+		return
+	}
+	assert.Equal(t, title, feed.Title)
+	assert.Equal(t, "© 2020 Podpal & Co", feed.Copyright)
+	assert.Equal(t, "Aaron's & Co", feed.ITunesExt.Author)
+	if assert.Len(t, feed.ITunesExt.Categories, 1) {
+		assert.Equal(t, "Religion & Spirituality", feed.ITunesExt.Categories[0].Text)
+		assert.Equal(t, "Christianity", feed.ITunesExt.Categories[0].Subcategory.Text)
+	}
+	if assert.Len(t, feed.Items, 1) {
+		assert.Equal(t, title, feed.Items[0].Title)
+	}
 }
@@ -0,0 +1,376 @@
+package podcast
+
+import (
+	"net/url"
+	"path"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Platform is a podcast directory with its own feed requirements.
+type Platform string
+
+// Platforms checked by Podcast.Validate.  PlatformRSS, the RSS 2.0
+// requirements every reader has, is always checked.
+const (
+	PlatformRSS     Platform = "rss"
+	PlatformApple   Platform = "apple"
+	PlatformSpotify Platform = "spotify"
+	PlatformGoogle  Platform = "google"
+)
+
+// Severity of a ValidationIssue.
+type Severity int
+
+// Severities of a ValidationIssue.  An error gets the feed or episode
+// rejected by the platform, a warning is a recommendation.
+const (
+	SeverityError Severity = iota
+	SeverityWarning
+)
+
+// String returns the name of the Severity.
+func (s Severity) String() string {
+	if s == SeverityWarning {
+		return "warning"
+	}
+	return "error"
+}
+
+// ValidationIssue is a problem found in the feed by Podcast.Validate.
+type ValidationIssue struct {
+	Platform Platform
+	Severity Severity
+
+	// Field is the path of the tag or attribute at fault, e.g.
+	// "channel/itunes:owner/itunes:email" or "channel/item/enclosure/@length".
+	Field string
+
+	// ItemIndex is the index of the Item in Podcast.Items, or -1 when the
+	// issue is with the channel.
+	ItemIndex int
+
+	Message string
+}
+
+// String formats the issue as "severity: platform: field: message".
+func (v ValidationIssue) String() string {
+	field := v.Field
+	if v.ItemIndex >= 0 {
+		field = strings.Replace(field, "item", "item["+strconv.Itoa(v.ItemIndex)+"]", 1)
+	}
+	return v.Severity.String() + ": " + string(v.Platform) + ": " + field + ": " + v.Message
+}
+
+// Apple Podcasts length limits.
+const (
+	appleDescriptionLimit = 4000
+	appleSubtitleLimit    = 255
+)
+
+// Validate checks the Podcast against the requirements of the target
+// platforms, or of all platforms when none are given, and returns the
+// issues found.  The RSS 2.0 requirements are always checked.
+//
+// Validate does not change the Podcast, so it can be called on a feed built
+// from the structs directly.  The typed fields are checked as they would be
+// encoded.  Issues are ordered channel first, then by Item.
+func (p *Podcast) Validate(targets ...Platform) []ValidationIssue {
+	p = p.formatted()
+
+	v := &validator{targets: map[Platform]bool{}}
+	if len(targets) == 0 {
+		targets = []Platform{PlatformApple, PlatformSpotify, PlatformGoogle}
+	}
+	for _, t := range targets {
+		v.targets[t] = true
+	}
+
+	v.channel(p)
+	v.extensions(-1, "channel", p.Extensions, p.Namespaces)
+	guids := map[string]int{}
+	for n, i := range p.Items {
+		v.item(n, i)
+		v.extensions(n, "channel/item", i.Extensions, p.Namespaces)
+		if i.GUID == nil || len(i.GUID.Value) == 0 {
+			continue
+		}
+		if first, ok := guids[i.GUID.Value]; ok {
+			v.add(PlatformRSS, SeverityError, n, "channel/item/guid",
+				"duplicate GUID "+i.GUID.Value+" of item "+strconv.Itoa(first))
+			continue
+		}
+		guids[i.GUID.Value] = n
+	}
+	return v.issues
+}
+
+type validator struct {
+	targets map[Platform]bool
+	issues  []ValidationIssue
+}
+
+// add records the issue when the platform is one of the targets.
+func (v *validator) add(platform Platform, severity Severity, item int, field, message string) {
+	if platform != PlatformRSS && !v.targets[platform] {
+		return
+	}
+	v.issues = append(v.issues, ValidationIssue{
+		Platform:  platform,
+		Severity:  severity,
+		Field:     field,
+		ItemIndex: item,
+		Message:   message,
+	})
+}
+
+// extensions checks the namespaces of the Extensions are declared.
+func (v *validator) extensions(n int, path string, xs []*Extension, namespaces map[string]string) {
+	for _, x := range xs {
+		if x == nil || len(x.Prefix) == 0 {
+			continue
+		}
+		if _, ok := builtinNamespaces[x.Prefix]; ok || len(namespaces[x.Prefix]) > 0 {
+			continue
+		}
+		v.add(PlatformRSS, SeverityError, n, path+"/"+qualifiedName(x.Prefix, x.Name),
+			"namespace "+x.Prefix+" is not declared")
+	}
+}
+
+func (v *validator) channel(p *Podcast) {
+	const c = -1
+
+	if len(strings.TrimSpace(p.Title)) == 0 {
+		v.add(PlatformRSS, SeverityError, c, "channel/title", "title is required")
+	}
+	if len(p.Link) == 0 {
+		v.add(PlatformRSS, SeverityError, c, "channel/link", "link is required")
+	}
+	if p.Description == nil || len(strings.TrimSpace(p.Description.Text)) == 0 {
+		v.add(PlatformRSS, SeverityError, c, "channel/description", "description is required")
+	} else if utf8.RuneCountInString(p.Description.Text) > appleDescriptionLimit {
+		v.add(PlatformApple, SeverityError, c, "channel/description", "description is longer than 4000 characters")
+	}
+	if p.ISummary != nil && utf8.RuneCountInString(p.ISummary.Text) > appleDescriptionLimit {
+		v.add(PlatformApple, SeverityError, c, "channel/itunes:summary", "summary is longer than 4000 characters")
+	}
+	if utf8.RuneCountInString(p.ISubtitle) > appleSubtitleLimit {
+		v.add(PlatformApple, SeverityWarning, c, "channel/itunes:subtitle", "subtitle is longer than 255 characters")
+	}
+
+	if len(p.PubDate) > 0 && !isDate(p.PubDate) {
+		v.add(PlatformRSS, SeverityError, c, "channel/pubDate", p.PubDate+" is not an RFC 2822 date")
+	}
+	if len(p.LastBuildDate) > 0 && !isDate(p.LastBuildDate) {
+		v.add(PlatformRSS, SeverityError, c, "channel/lastBuildDate", p.LastBuildDate+" is not an RFC 2822 date")
+	}
+
+	if len(p.Language) == 0 {
+		v.add(PlatformApple, SeverityError, c, "channel/language", "language is required")
+		v.add(PlatformSpotify, SeverityError, c, "channel/language", "language is required")
+	} else {
+		v.language(c, "channel/language", p.Language)
+	}
+
+	// artwork
+	hasIImage := p.IImage != nil && len(p.IImage.HREF) > 0
+	if !hasIImage {
+		v.add(PlatformApple, SeverityError, c, "channel/itunes:image", "artwork is required")
+	} else if !isArtworkURL(p.IImage.HREF) {
+		v.add(PlatformApple, SeverityWarning, c, "channel/itunes:image/@href", "artwork should be a .jpg or .png file")
+	}
+	if !hasIImage && (p.Image == nil || len(p.Image.URL) == 0) {
+		v.add(PlatformSpotify, SeverityError, c, "channel/image", "artwork is required")
+	}
+	if !hasIImage && (p.GooglePlayImage == nil || len(p.GooglePlayImage.HREF) == 0) {
+		v.add(PlatformGoogle, SeverityError, c, "channel/googleplay:image", "artwork is required")
+	}
+
+	// owner
+	hasOwnerEmail := p.IOwner != nil && len(p.IOwner.Email) > 0
+	if !hasOwnerEmail {
+		v.add(PlatformApple, SeverityError, c, "channel/itunes:owner/itunes:email", "owner email is required")
+	}
+	if !hasOwnerEmail && len(p.GooglePlayEmail) == 0 {
+		v.add(PlatformGoogle, SeverityError, c, "channel/googleplay:email", "owner email is required")
+	}
+	if len(p.IAuthor) == 0 {
+		v.add(PlatformApple, SeverityWarning, c, "channel/itunes:author", "author is recommended")
+	}
+
+	// explicit
+	if len(p.IExplicit) == 0 {
+		v.add(PlatformApple, SeverityError, c, "channel/itunes:explicit", "explicit is required")
+	} else if !isExplicitValue(p.IExplicit) {
+		v.add(PlatformApple, SeverityError, c, "channel/itunes:explicit", p.IExplicit+" is not a valid explicit value")
+	}
+
+	if len(p.IType) > 0 && p.IType != "episodic" && p.IType != "serial" {
+		v.add(PlatformApple, SeverityError, c, "channel/itunes:type", p.IType+" is not episodic or serial")
+	}
+
+	// categories
+	if len(p.ICategories) == 0 {
+		v.add(PlatformApple, SeverityError, c, "channel/itunes:category", "a category is required")
+	}
+	for _, cat := range p.ICategories {
+		category := Category(cat.Text)
+		if category.index() < 0 {
+			v.add(PlatformApple, SeverityError, c, "channel/itunes:category/@text", cat.Text+" is not an Apple Podcasts category")
+			continue
+		}
+		for _, sub := range cat.ICategories {
+			if category.subcategoryIndex(Category(sub.Text)) < 0 {
+				v.add(PlatformApple, SeverityError, c, "channel/itunes:category/itunes:category/@text",
+					sub.Text+" is not a subcategory of "+cat.Text)
+			}
+		}
+	}
+	for _, cat := range p.GooglePlayCategories {
+		if !isGooglePlayCategory(cat.Text) {
+			v.add(PlatformGoogle, SeverityWarning, c, "channel/googleplay:category/@text", cat.Text+" is not a Google Podcasts category")
+		}
+	}
+
+	if len(p.SpotifyCountryOfOrigin) > 0 {
+		for _, country := range strings.Fields(p.SpotifyCountryOfOrigin) {
+			if _, err := parseCountryCode(country); err != nil {
+				v.add(PlatformSpotify, SeverityError, c, "channel/spotify:countryOfOrigin", err.Error())
+			}
+		}
+	}
+}
+
+func (v *validator) item(n int, i *Item) {
+	if len(strings.TrimSpace(i.Title)) == 0 {
+		v.add(PlatformRSS, SeverityError, n, "channel/item/title", "title is required")
+	}
+	if i.GUID == nil || len(i.GUID.Value) == 0 {
+		v.add(PlatformApple, SeverityError, n, "channel/item/guid", "guid is required")
+		v.add(PlatformSpotify, SeverityError, n, "channel/item/guid", "guid is required")
+	}
+	if len(i.PubDate) == 0 {
+		v.add(PlatformSpotify, SeverityError, n, "channel/item/pubDate", "pubDate is required")
+		v.add(PlatformGoogle, SeverityWarning, n, "channel/item/pubDate", "pubDate is recommended")
+	} else if !isDate(i.PubDate) {
+		v.add(PlatformRSS, SeverityError, n, "channel/item/pubDate", i.PubDate+" is not an RFC 2822 date")
+	}
+	if i.Description != nil && utf8.RuneCountInString(i.Description.Text) > appleDescriptionLimit {
+		v.add(PlatformApple, SeverityError, n, "channel/item/description", "description is longer than 4000 characters")
+	}
+	if i.ISummary != nil && utf8.RuneCountInString(i.ISummary.Text) > appleDescriptionLimit {
+		v.add(PlatformApple, SeverityError, n, "channel/item/itunes:summary", "summary is longer than 4000 characters")
+	}
+
+	if i.Enclosure == nil {
+		if len(i.Link) == 0 {
+			v.add(PlatformRSS, SeverityError, n, "channel/item/link", "link is required when not using an enclosure")
+		}
+		for _, platform := range []Platform{PlatformApple, PlatformSpotify, PlatformGoogle} {
+			v.add(platform, SeverityError, n, "channel/item/enclosure", "enclosure is required")
+		}
+	} else {
+		v.enclosure(n, i.Enclosure)
+	}
+
+	if len(i.EpisodeType) > 0 && i.EpisodeType != EpisodeTypeFull &&
+		i.EpisodeType != EpisodeTypeTrailer && i.EpisodeType != EpisodeTypeBonus {
+		v.add(PlatformApple, SeverityError, n, "channel/item/itunes:episodeType", i.EpisodeType+" is not full, trailer or bonus")
+	}
+	if len(i.IExplicit) > 0 && !isExplicitValue(i.IExplicit) {
+		v.add(PlatformApple, SeverityError, n, "channel/item/itunes:explicit", i.IExplicit+" is not a valid explicit value")
+	}
+	if len(i.IDuration) == 0 {
+		v.add(PlatformApple, SeverityWarning, n, "channel/item/itunes:duration", "duration is recommended")
+	}
+
+	for _, t := range i.Transcripts {
+		if t != nil && len(t.Language) > 0 {
+			v.language(n, "channel/item/podcast:transcript/@language", t.Language)
+		}
+	}
+	for _, a := range i.AlternateEnclosures {
+		if a != nil && len(a.Language) > 0 {
+			v.language(n, "channel/item/podcast:alternateEnclosure/@lang", a.Language)
+		}
+	}
+}
+
+// language reports the tag if it is not a BCP 47 language tag, or warns if
+// it is not in the canonical form of ParseLanguage.
+func (v *validator) language(n int, field, tag string) {
+	canonical, err := ParseLanguage(tag)
+	if err != nil {
+		v.add(PlatformRSS, SeverityError, n, field, tag+" is not a BCP 47 language tag")
+	} else if canonical != tag {
+		v.add(PlatformRSS, SeverityWarning, n, field, tag+" should be written "+canonical)
+	}
+}
+
+func (v *validator) enclosure(n int, e *Enclosure) {
+	if len(e.URL) == 0 {
+		v.add(PlatformRSS, SeverityError, n, "channel/item/enclosure/@url", "url is required")
+	} else if u, err := url.Parse(e.URL); err != nil || (u.Scheme != "http" && u.Scheme != "https") {
+		v.add(PlatformRSS, SeverityError, n, "channel/item/enclosure/@url", e.URL+" is not an http(s) url")
+	}
+
+	t := e.TypeFormatted
+	if len(t) == 0 {
+		t = e.Type.String()
+	}
+	if t == enclosureDefault {
+		v.add(PlatformRSS, SeverityError, n, "channel/item/enclosure/@type", "type is required")
+	} else if !isAppleEnclosureType(t) {
+		v.add(PlatformApple, SeverityError, n, "channel/item/enclosure/@type", t+" is not supported by Apple Podcasts")
+	}
+
+	if e.Length <= 0 && (len(e.LengthFormatted) == 0 || e.LengthFormatted == "0") {
+		v.add(PlatformApple, SeverityError, n, "channel/item/enclosure/@length", "length in bytes is required")
+	}
+}
+
+// isDate reports whether the date is in the RFC 2822 format RSS 2.0
+// requires.
+func isDate(date string) bool {
+	_, ok := parseRFC2822Date(date)
+	return ok
+}
+
+// isExplicitValue reports whether the itunes:explicit value is one Apple
+// accepts.
+func isExplicitValue(explicit string) bool {
+	switch strings.ToLower(explicit) {
+	case "true", "false", "yes", "no", "clean", "explicit":
+		return true
+	}
+	return false
+}
+
+// isArtworkURL reports whether the url is a JPEG or PNG image, the formats
+// Apple accepts for artwork.
+func isArtworkURL(artwork string) bool {
+	u, err := url.Parse(artwork)
+	if err != nil {
+		return false
+	}
+	switch strings.ToLower(path.Ext(u.Path)) {
+	case ".jpg", ".jpeg", ".png":
+		return true
+	}
+	return false
+}
+
+func isAppleEnclosureType(t string) bool {
+	for _, et := range []EnclosureType{M4A, M4V, MP4, MP3, MOV, PDF, EPUB} {
+		if et.String() == t {
+			return true
+		}
+	}
+	return false
+}
+
+func isGooglePlayCategory(category string) bool {
+	return googlePlayCategoryIndex(category) >= 0
+}
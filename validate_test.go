@@ -0,0 +1,189 @@
+package podcast_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	podcast "github.com/podpalinc/rss-feed-generator"
+	"github.com/stretchr/testify/assert"
+)
+
+// newValidPodcast returns a Podcast without issues on any Platform.
+func newValidPodcast(t *testing.T) podcast.Podcast {
+	p := newTestPodcast(t, "Episode 1")
+	p.AddLanguage("en-us")
+	p.AddImage("http://example.com/i.jpg")
+	p.AddOwner("Jane", "jane@example.com")
+	p.AddAuthor([]string{"Jane"})
+	p.AddParentalAdvisory(podcast.ParentalAdvisoryClean)
+	if err := p.AddCategory("Arts", []string{"Books"}); err != nil {
+		t.Fatal(err)
+	}
+	p.Items[0].AddPubDate("Sun, 14 Mar 2021 18:34:05 +0000")
+	p.Items[0].AddDuration(533)
+	return p
+}
+
+func issueFields(issues []podcast.ValidationIssue) []string {
+	var fields []string
+	for _, i := range issues {
+		fields = append(fields, string(i.Platform)+" "+i.Field)
+	}
+	return fields
+}
+
+func TestValidateValid(t *testing.T) {
+	t.Parallel()
+
+	p := newValidPodcast(t)
+
+	assert.Empty(t, p.Validate())
+}
+
+func TestValidateDecoded(t *testing.T) {
+	t.Parallel()
+
+	p := newValidPodcast(t)
+
+	d := reencode(t, &p)
+
+	assert.Empty(t, d.Validate())
+}
+
+func TestValidateTargets(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("title", "http://example.com/", podcast.Description{Text: "Description"}, nil, nil)
+	p.AddLanguage("en-us")
+
+	// act
+	issues := p.Validate(podcast.PlatformGoogle)
+
+	// assert
+	assert.Equal(t, []string{
+		"google googleplay:image",
+		"google googleplay:email",
+	}, trimChannel(issueFields(issues)))
+}
+
+func TestValidateChannel(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := podcast.New("", "", podcast.Description{}, nil, nil)
+	p.Language = "e1-!!"
+	p.AddImage("http://example.com/i.gif")
+	p.IExplicit = "maybe"
+	p.AddItunesType("daily")
+	p.ICategories = []*podcast.ICategory{
+		{Text: "Bombay"},
+		{Text: "Arts", ICategories: []*podcast.ICategory{{Text: "Physics"}}},
+	}
+	p.SpotifyCountryOfOrigin = "us zz"
+
+	// act
+	issues := p.Validate(podcast.PlatformApple, podcast.PlatformSpotify)
+
+	// assert
+	assert.Equal(t, []string{
+		"rss title",
+		"rss link",
+		"rss description",
+		"rss language",
+		"apple itunes:image/@href",
+		"apple itunes:owner/itunes:email",
+		"apple itunes:author",
+		"apple itunes:explicit",
+		"apple itunes:type",
+		"apple itunes:category/@text",
+		"apple itunes:category/itunes:category/@text",
+		"spotify spotify:countryOfOrigin",
+	}, trimChannel(issueFields(issues)))
+	for _, i := range issues {
+		assert.Equal(t, -1, i.ItemIndex)
+	}
+}
+
+func TestValidateItems(t *testing.T) {
+	t.Parallel()
+
+	// arrange
+	p := newValidPodcast(t)
+	p.Items = append(p.Items,
+		&podcast.Item{
+			GUID:        p.Items[0].GUID,
+			Title:       "Duplicate",
+			EpisodeType: "teaser",
+			Enclosure:   &podcast.Enclosure{URL: "ftp://example.com/2.ogg", TypeFormatted: "audio/ogg"},
+		},
+		&podcast.Item{Link: "http://example.com/3"},
+	)
+
+	// act
+	issues := p.Validate(podcast.PlatformApple)
+
+	// assert
+	var got []string
+	for _, i := range issues {
+		got = append(got, i.String())
+	}
+	assert.Equal(t, []string{
+		"error: rss: channel/item[1]/enclosure/@url: ftp://example.com/2.ogg is not an http(s) url",
+		"error: apple: channel/item[1]/enclosure/@type: audio/ogg is not supported by Apple Podcasts",
+		"error: apple: channel/item[1]/enclosure/@length: length in bytes is required",
+		"error: apple: channel/item[1]/itunes:episodeType: teaser is not full, trailer or bonus",
+		"warning: apple: channel/item[1]/itunes:duration: duration is recommended",
+		"error: rss: channel/item[1]/guid: duplicate GUID http://example.com/1.mp3 of item 0",
+		"error: rss: channel/item[2]/title: title is required",
+		"error: apple: channel/item[2]/guid: guid is required",
+		"error: apple: channel/item[2]/enclosure: enclosure is required",
+		"warning: apple: channel/item[2]/itunes:duration: duration is recommended",
+	}, got)
+}
+
+func TestValidateLengthLimits(t *testing.T) {
+	t.Parallel()
+
+	p := newValidPodcast(t)
+	p.AddSummary(strings.Repeat("a", 4000))
+	p.Description.Text = strings.Repeat("a", 4001)
+	p.Items[0].Description.Text = strings.Repeat("a", 4001)
+
+	issues := p.Validate(podcast.PlatformApple)
+
+	assert.Equal(t, []string{
+		"apple channel/description",
+		"apple channel/item/description",
+	}, issueFields(issues))
+}
+
+// trimChannel removes the channel/ prefix of the fields for brevity.
+func trimChannel(fields []string) []string {
+	for n, f := range fields {
+		fields[n] = strings.Replace(f, "channel/", "", 1)
+	}
+	return fields
+}
+
+func TestValidatePubDate(t *testing.T) {
+	t.Parallel()
+
+	p := newValidPodcast(t)
+	p.AddPubDate("2021-03-14")
+	p.Items[0].AddPubDate("14/03/2021")
+
+	issues := p.Validate(podcast.PlatformApple)
+
+	assert.Equal(t, []string{
+		"rss channel/pubDate",
+		"rss channel/item/pubDate",
+	}, issueFields(issues))
+
+	// the typed fields are validated as encoded
+	p.AddPubDateTime(time.Now())
+	p.Items[0].AddPubDateTime(time.Now())
+
+	assert.Empty(t, p.Validate(podcast.PlatformApple))
+}
@@ -0,0 +1,94 @@
+package podcast
+
+import (
+	"encoding/xml"
+	"strconv"
+
+	"github.com/pkg/errors"
+)
+
+// Common podcast:value types, methods and recipient types.
+const (
+	ValueTypeLightning = "lightning"
+	ValueMethodKeysend = "keysend"
+
+	RecipientTypeNode      = "node"
+	RecipientTypeLNAddress = "lnaddress"
+)
+
+// Value represents a podcast:value block describing how listeners can send
+// payments to the people behind a show or episode.
+//
+// A Value set on an Item overrides the Value of the Podcast for that episode.
+//
+// Specification: https://github.com/Podcastindex-org/podcast-namespace/blob/main/value/value.md
+type Value struct {
+	XMLName    xml.Name          `xml:"podcast:value"`
+	Type       string            `xml:"type,attr"`
+	Method     string            `xml:"method,attr"`
+	Suggested  string            `xml:"suggested,attr,omitempty"`
+	Recipients []*ValueRecipient `xml:"podcast:valueRecipient"`
+}
+
+// ValueRecipient represents a podcast:valueRecipient tag, one destination
+// of the payments described by a Value.
+//
+// Split is the number of shares of each payment the recipient receives,
+// relative to the other recipients.  When Fee is set, Split is instead a
+// percentage taken off the top of the payment, e.g. by the hosting company.
+type ValueRecipient struct {
+	XMLName     xml.Name `xml:"podcast:valueRecipient"`
+	Name        string   `xml:"name,attr,omitempty"`
+	CustomKey   string   `xml:"customKey,attr,omitempty"`
+	CustomValue string   `xml:"customValue,attr,omitempty"`
+	Type        string   `xml:"type,attr"`
+	Address     string   `xml:"address,attr"`
+	Split       int      `xml:"split,attr"`
+	Fee         bool     `xml:"fee,attr,omitempty"`
+}
+
+// validateValue confirms the Value has a type, a method and well-formed
+// recipient splits.
+func validateValue(v *Value) error {
+	if len(v.Type) == 0 || len(v.Method) == 0 {
+		return errors.New("Value.Type and Value.Method are required")
+	}
+	if len(v.Recipients) == 0 {
+		return errors.New("Value requires at least one recipient")
+	}
+
+	shares, fees := 0, 0
+	for n, r := range v.Recipients {
+		if err := validateValueRecipient(r); err != nil {
+			return errors.Wrap(err, "Value.Recipients["+strconv.Itoa(n)+"]")
+		}
+		if r.Fee {
+			fees += r.Split
+		} else {
+			shares += r.Split
+		}
+	}
+	if shares == 0 {
+		return errors.New("Value requires at least one recipient that is not a fee")
+	}
+	if fees >= 100 {
+		return errors.New("Value fees must total less than 100 percent")
+	}
+	return nil
+}
+
+func validateValueRecipient(r *ValueRecipient) error {
+	if r == nil {
+		return errors.New("recipient is nil")
+	}
+	if len(r.Type) == 0 || len(r.Address) == 0 {
+		return errors.New("Type and Address are required")
+	}
+	if r.Split <= 0 {
+		return errors.New(r.Address + ": Split must be greater than zero")
+	}
+	if len(r.CustomValue) > 0 && len(r.CustomKey) == 0 {
+		return errors.New(r.Address + ": CustomKey is required with CustomValue")
+	}
+	return nil
+}